@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"final-by-me/internal/fixtures"
+	"final-by-me/internal/importer"
+	"final-by-me/internal/scores"
+)
+
+const usage = `usage:
+  final-by-me                              start the HTTP server
+  final-by-me reconcile-scores [-repair]   compare stored scores with goal events
+  final-by-me rebuild-standings [-league L] [-season S]
+                                           recompute the standings projection from the matches
+  final-by-me check-standings [-league L] [-repair]
+                                           compare the standings projection with a full recomputation
+  final-by-me generate-fixtures -league L -start RFC3339 [-season S] [-spacing 7] [-blackouts D,D] [-commit]
+                                           print a double round-robin schedule; -commit inserts it
+  final-by-me import-matches [-format csv|json] [-tz Europe/London] [-dry-run] FILE
+                                           import fixtures and results; rows imported before are skipped
+`
+
+// runCommand runs a CLI subcommand against the configured storage and returns the exit code.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "reconcile-scores":
+		return cmdReconcileScores(args[1:])
+	case "rebuild-standings":
+		return cmdRebuildStandings(args[1:])
+	case "check-standings":
+		return cmdCheckStandings(args[1:])
+	case "generate-fixtures":
+		return cmdGenerateFixtures(args[1:])
+	case "import-matches":
+		return cmdImportMatches(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func cmdReconcileScores(args []string) int {
+	fs := flag.NewFlagSet("reconcile-scores", flag.ContinueOnError)
+	repair := fs.Bool("repair", false, "rewrite stored scores from the goal events")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rep, err := scores.Reconcile(ctx, st.matches, st.events, *repair)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile error:", err)
+		return 1
+	}
+	if err := st.projector().ApplyKeys(ctx, rep.RepairedKeys()...); err != nil {
+		fmt.Fprintln(os.Stderr, "standings error:", err)
+		return 1
+	}
+	return printJSON(rep)
+}
+
+func cmdRebuildStandings(args []string) int {
+	fs := flag.NewFlagSet("rebuild-standings", flag.ContinueOnError)
+	league := fs.String("league", "", "only this league")
+	season := fs.String("season", "", "only this season of -league (none: matches without a season)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *season != "" && *league == "" {
+		fmt.Fprintln(os.Stderr, "-season requires -league")
+		return 2
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	p := st.projector()
+	var ids []string
+	if *season != "" {
+		id := *season
+		if id == "none" {
+			id = ""
+		}
+		s, err := p.Rebuild(ctx, *league, id)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "rebuild error:", err)
+			return 1
+		}
+		ids = []string{s.ID}
+	} else {
+		var err error
+		if ids, err = p.RebuildAll(ctx, *league); err != nil {
+			fmt.Fprintln(os.Stderr, "rebuild error:", err)
+			return 1
+		}
+	}
+	return printJSON(map[string]any{"rebuilt": ids, "count": len(ids)})
+}
+
+func cmdCheckStandings(args []string) int {
+	fs := flag.NewFlagSet("check-standings", flag.ContinueOnError)
+	league := fs.String("league", "", "only this league")
+	repair := fs.Bool("repair", false, "rebuild the standings that disagree")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rep, err := st.projector().Check(ctx, *league, *repair)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check error:", err)
+		return 1
+	}
+	return printJSON(rep)
+}
+
+func cmdGenerateFixtures(args []string) int {
+	fs := flag.NewFlagSet("generate-fixtures", flag.ContinueOnError)
+	league := fs.String("league", "", "league to schedule")
+	season := fs.String("season", "", "season id (default: the league season covering -start)")
+	start := fs.String("start", "", "kick-off of matchday 1 (RFC3339)")
+	spacing := fs.Int("spacing", fixtures.DefaultSpacing, "days between matchdays")
+	blackouts := fs.String("blackouts", "", "comma-separated days without matches (YYYY-MM-DD)")
+	commit := fs.Bool("commit", false, "insert the fixtures instead of only printing them")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	req := fixtures.Request{League: *league, Season: *season, Spacing: *spacing}
+	var err error
+	if req.Start, err = time.Parse(time.RFC3339, *start); err != nil || *league == "" {
+		fmt.Fprintln(os.Stderr, "-league and -start (RFC3339) are required")
+		return 2
+	}
+	for _, d := range strings.Split(*blackouts, ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, d)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "-blackouts must be YYYY-MM-DD")
+			return 2
+		}
+		req.Blackouts = append(req.Blackouts, day)
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sched, msg, err := fixtures.Plan(ctx, st.teams, st.seasons, st.matches, req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "schedule error:", err)
+		return 1
+	}
+	if msg != "" {
+		fmt.Fprintln(os.Stderr, msg)
+		return 1
+	}
+	if !*commit {
+		return printJSON(sched)
+	}
+	n, err := st.matches.CreateMany(ctx, sched.Fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "insert error after %d fixtures: %v\n", n, err)
+		return 1
+	}
+	return printJSON(map[string]any{"inserted": n, "schedule": sched})
+}
+
+func cmdImportMatches(args []string) int {
+	fs := flag.NewFlagSet("import-matches", flag.ContinueOnError)
+	format := fs.String("format", "", "csv or json (default: from the file extension)")
+	tz := fs.String("tz", "UTC", "time zone of CSV dates")
+	dryRun := fs.Bool("dry-run", false, "validate and report without inserting")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "import-matches needs one FILE")
+		return 2
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = "json"
+		if strings.HasSuffix(strings.ToLower(path), ".csv") {
+			*format = "csv"
+		}
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unknown -tz:", err)
+		return 2
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+
+	var rows []importer.Row
+	switch *format {
+	case "csv":
+		rows, err = importer.ParseCSV(f, loc)
+	case "json":
+		rows, err = importer.ParseJSON(f)
+	default:
+		fmt.Fprintln(os.Stderr, "-format must be csv or json")
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rep, err := importer.Import(ctx, st.teams, st.seasons, st.players, st.matches, rows, *dryRun, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import error:", err)
+		return 1
+	}
+	projector := st.projector()
+	for _, m := range rep.Matches {
+		if err := projector.Apply(ctx, m); err != nil {
+			fmt.Fprintln(os.Stderr, "standings not updated, run rebuild-standings:", err)
+			break
+		}
+	}
+	return printJSON(rep)
+}
+
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
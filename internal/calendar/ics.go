@@ -0,0 +1,195 @@
+// Package calendar renders matches as iCalendar (RFC 5545) feeds.
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+// UIDDomain is the right-hand side of every event UID.
+const UIDDomain = "final-by-me"
+
+// matchLength is the DTEND of an event: 90 minutes plus half-time and stoppage.
+const matchLength = 2 * time.Hour
+
+const stampLayout = "20060102T150405Z"
+
+// Feed is one calendar: a team's or a league's matches.
+type Feed struct {
+	Name    string                 // X-WR-CALNAME
+	Matches []models.Match         // in any order
+	Teams   map[string]models.Team // by code, for the event summaries
+}
+
+// Write renders f. The output only depends on the matches, so an unchanged
+// feed renders byte for byte the same (see the ETag of the handler).
+func (f Feed) Write(w io.Writer) error {
+	cw := &contentWriter{w: w}
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//" + UIDDomain + "//fixtures//EN")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	cw.line("X-WR-CALNAME:" + escape(f.Name))
+	cw.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	cw.line("X-PUBLISHED-TTL:PT1H")
+	for _, m := range f.Matches {
+		f.event(cw, m)
+	}
+	cw.line("END:VCALENDAR")
+	return cw.err
+}
+
+func (f Feed) event(cw *contentWriter, m models.Match) {
+	stamp := Modified(m).UTC().Format(stampLayout)
+	start := m.DateTime.UTC()
+
+	cw.line("BEGIN:VEVENT")
+	cw.line("UID:" + UID(m.MatchKey))
+	cw.line("DTSTAMP:" + stamp)
+	cw.line("LAST-MODIFIED:" + stamp)
+	cw.line(fmt.Sprintf("SEQUENCE:%d", Sequence(m)))
+	cw.line("DTSTART:" + start.Format(stampLayout))
+	cw.line("DTEND:" + start.Add(matchLength).Format(stampLayout))
+	cw.line("SUMMARY:" + escape(f.summary(m)))
+	if d := description(m); d != "" {
+		cw.line("DESCRIPTION:" + escape(d))
+	}
+	cw.line("STATUS:" + status(m.Status))
+	cw.line("END:VEVENT")
+}
+
+// UID identifies the event of a match across feeds and reschedules.
+func UID(matchKey string) string {
+	return matchKey + "@" + UIDDomain
+}
+
+// Sequence is the revision of the event: it goes up with every change of
+// the kick-off or the status (postponed, cancelled, ...), so calendars
+// replace the old event instead of keeping it.
+func Sequence(m models.Match) int {
+	return len(m.Reschedules) + len(m.Transitions)
+}
+
+// Modified is the last recorded change of m: creation, a status or period
+// change, a reschedule, an event, a shootout kick or the award.
+func Modified(m models.Match) time.Time {
+	last := m.ID.Timestamp()
+	later := func(t time.Time) {
+		if t.After(last) {
+			last = t
+		}
+	}
+	for _, c := range m.Transitions {
+		later(c.At)
+	}
+	for _, rs := range m.Reschedules {
+		later(rs.At)
+	}
+	for _, p := range m.Periods {
+		later(p.At)
+	}
+	for _, e := range m.Events {
+		later(e.CreatedAt)
+	}
+	if m.Shootout != nil {
+		for _, k := range m.Shootout.Kicks {
+			later(k.CreatedAt)
+		}
+	}
+	if m.Award != nil {
+		later(m.Award.At)
+	}
+	return last
+}
+
+// summary is "Arsenal vs Chelsea", or the score once there is a result.
+func (f Feed) summary(m models.Match) string {
+	home, away := f.name(m.HomeCode), f.name(m.AwayCode)
+	switch m.Status {
+	case models.Finished, models.Awarded:
+		hg, ag := m.Result()
+		s := fmt.Sprintf("%s %d-%d %s", home, hg, ag, away)
+		if m.Status == models.Awarded {
+			s += " (awarded)"
+		} else if m.Shootout != nil && m.Shootout.Completed {
+			s += fmt.Sprintf(" (%d-%d pens)", m.Shootout.HomeScore, m.Shootout.AwayScore)
+		}
+		return s
+	case models.Live:
+		return fmt.Sprintf("%s %d-%d %s (live)", home, m.HomeGoals, m.AwayGoals, away)
+	case models.Postponed, models.Cancelled, models.Abandoned, models.Suspended:
+		return fmt.Sprintf("%s vs %s (%s)", home, away, m.Status)
+	}
+	return home + " vs " + away
+}
+
+func (f Feed) name(code string) string {
+	if t, ok := f.Teams[code]; ok && t.Name != "" {
+		return t.Name
+	}
+	return code
+}
+
+// description is the season and round of m.
+func description(m models.Match) string {
+	var parts []string
+	if m.Season != "" {
+		parts = append(parts, m.Season)
+	}
+	if m.Matchday > 0 {
+		parts = append(parts, fmt.Sprintf("Matchday %d", m.Matchday))
+	}
+	if m.Knockout {
+		parts = append(parts, "Knockout")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// status maps the match lifecycle onto VEVENT STATUS.
+func status(s models.MatchStatus) string {
+	switch s {
+	case models.Cancelled:
+		return "CANCELLED"
+	case models.Postponed, models.Suspended, models.Abandoned:
+		return "TENTATIVE"
+	}
+	return "CONFIRMED"
+}
+
+// escape quotes a TEXT value (RFC 5545 3.3.11).
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// contentWriter writes content lines: CRLF-terminated and folded at 75
+// octets without splitting a UTF-8 sequence (RFC 5545 3.1).
+type contentWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *contentWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	_, cw.err = io.WriteString(cw.w, b.String())
+}
@@ -0,0 +1,236 @@
+// Package discipline turns the cards of match events into suspensions.
+package discipline
+
+import (
+	"sort"
+	"strings"
+
+	"final-by-me/internal/models"
+)
+
+// Ban reasons
+const (
+	ReasonYellows      = "yellow_accumulation"
+	ReasonRed          = "red_card"
+	ReasonSecondYellow = "second_yellow"
+)
+
+// Statuses are the statuses of the matches a Book is built from: the
+// matches that were played or are being played.
+var Statuses = []models.MatchStatus{models.Live, models.Finished, models.Awarded}
+
+// Ban is a suspension for the next Matches matches of the player's team in
+// the competition, whichever team that is by then. Bans of a player are
+// served one after the other.
+type Ban struct {
+	Reason   string `json:"reason"`
+	MatchKey string `json:"matchKey"` // match the ban was incurred in
+	Matches  int    `json:"matches"`
+	Served   int    `json:"served"`
+
+	at int // position of the match in the book
+}
+
+// Record is the disciplinary record of a player in one competition.
+type Record struct {
+	PlayerID string `json:"playerId,omitempty"` // "" for free-text (legacy) names
+	Player   string `json:"player"`
+	TeamCode string `json:"teamCode"` // team of the player's latest event
+
+	// Yellows count towards accumulation: the two yellows of a sending-off
+	// are left out and counted once in SecondYellows.
+	Yellows       int   `json:"yellows"`
+	SecondYellows int   `json:"secondYellows"`
+	Reds          int   `json:"reds"` // straight red cards
+	Bans          []Ban `json:"bans"`
+
+	Suspended bool `json:"suspended"`
+	Remaining int  `json:"remaining"` // matches still to serve
+}
+
+// Points are the fair play points of r (see models.FairPlayYellow).
+func (r Record) Points() int {
+	return r.Yellows*models.FairPlayYellow + (r.SecondYellows+r.Reds)*models.FairPlayRed
+}
+
+// Book is the discipline of one competition.
+type Book struct {
+	rules   models.DisciplineRules
+	records map[string]*Record
+	at      map[string]int   // match key -> position in the book
+	played  map[string][]int // team -> positions of its matches
+}
+
+// Build replays the cards of matches, which should be the matches of one
+// competition that were played or are being played. Cards are counted once
+// their match is in the list; a ban starts with the next match of the team
+// the player last played for.
+func Build(matches []models.Match, rules models.DisciplineRules) *Book {
+	b := &Book{rules: rules, records: map[string]*Record{}, at: map[string]int{}, played: map[string][]int{}}
+
+	list := append([]models.Match{}, matches...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if !list[i].DateTime.Equal(list[j].DateTime) {
+			return list[i].DateTime.Before(list[j].DateTime)
+		}
+		return list[i].MatchKey < list[j].MatchKey
+	})
+	team := map[string]string{} // player key -> team of the latest event
+	for i, m := range list {
+		b.at[m.MatchKey] = i
+		b.played[m.HomeCode] = append(b.played[m.HomeCode], i)
+		b.played[m.AwayCode] = append(b.played[m.AwayCode], i)
+		b.cards(m, i)
+		for _, e := range m.Events {
+			for _, p := range [][2]string{{e.PlayerID, e.Player}, {e.PlayerOutID, e.PlayerOut}, {e.PlayerInID, e.PlayerIn}, {e.AssistID, e.Assist}} {
+				if p[0] != "" || p[1] != "" {
+					team[models.PlayerKey(p[0], p[1], e.TeamCode)] = e.TeamCode
+				}
+			}
+		}
+	}
+
+	for key, r := range b.records {
+		r.TeamCode = team[key]
+		played := len(b.played[r.TeamCode])
+		for i, start := range b.schedule(r, r.TeamCode) {
+			ban := &r.Bans[i]
+			ban.Served = min(ban.Matches, max(played-start, 0))
+			r.Remaining += ban.Matches - ban.Served
+		}
+		r.Suspended = r.Remaining > 0
+	}
+	return b
+}
+
+// schedule returns for each ban of r the index in the matches of team of the
+// first match it covers: the first after the ban's match, or after the
+// previous ban.
+func (b *Book) schedule(r *Record, team string) []int {
+	played := b.played[team]
+	out := make([]int, len(r.Bans))
+	next := 0
+	for i, ban := range r.Bans {
+		after := sort.SearchInts(played, ban.at+1)
+		out[i] = max(after, next)
+		next = out[i] + ban.Matches
+	}
+	return out
+}
+
+// cards books the cards of m, the match at position at.
+func (b *Book) cards(m models.Match, at int) {
+	events := append([]models.MatchEvent{}, m.Events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Before(events[j]) })
+
+	type inMatch struct {
+		yellows int
+		off     bool // sent off: later cards are ignored
+	}
+	seen := map[string]*inMatch{}
+	var order []string
+	before := map[string]int{} // yellows before the match
+
+	for _, e := range events {
+		if e.Type != models.EventCard {
+			continue
+		}
+		key := models.PlayerKey(e.PlayerID, e.Player, e.TeamCode)
+		r := b.record(key, e)
+		st, ok := seen[key]
+		if !ok {
+			st = &inMatch{}
+			seen[key] = st
+			order = append(order, key)
+			before[key] = r.Yellows
+		}
+		if st.off {
+			continue
+		}
+
+		switch e.CardColor {
+		case "yellow":
+			st.yellows++
+			if st.yellows == 1 {
+				r.Yellows++
+				continue
+			}
+			st.off = true
+			r.Yellows--
+			r.SecondYellows++
+			b.ban(r, ReasonSecondYellow, b.rules.SecondYellowBan, m, at)
+		case "red":
+			st.off = true
+			r.Reds++
+			b.ban(r, ReasonRed, b.rules.RedBan, m, at)
+		}
+	}
+
+	// accumulation is checked at the end of the match
+	for _, key := range order {
+		r := b.records[key]
+		for _, yb := range b.rules.YellowBans {
+			if before[key] < yb.Yellows && r.Yellows >= yb.Yellows {
+				b.ban(r, ReasonYellows, yb.Matches, m, at)
+			}
+		}
+	}
+}
+
+func (b *Book) record(key string, e models.MatchEvent) *Record {
+	r, ok := b.records[key]
+	if !ok {
+		r = &Record{PlayerID: e.PlayerID, Bans: []Ban{}}
+		b.records[key] = r
+	}
+	if e.Player != "" {
+		r.Player = e.Player
+	}
+	return r
+}
+
+// ban adds a ban incurred in m, the match at position at.
+func (b *Book) ban(r *Record, reason string, matches int, m models.Match, at int) {
+	if matches <= 0 {
+		return
+	}
+	r.Bans = append(r.Bans, Ban{Reason: reason, MatchKey: m.MatchKey, Matches: matches, at: at})
+}
+
+// Records lists the players with cards: suspended players first, then by
+// fair play points and name.
+func (b *Book) Records() []Record {
+	out := make([]Record, 0, len(b.records))
+	for _, r := range b.records {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, c := out[i], out[j]
+		switch {
+		case a.Suspended != c.Suspended:
+			return a.Suspended
+		case a.Points() != c.Points():
+			return a.Points() > c.Points()
+		}
+		return strings.ToLower(a.Player) < strings.ToLower(c.Player)
+	})
+	return out
+}
+
+// Suspension returns the ban that keeps player (a models.PlayerKey) out of
+// the match matchKey, which must be in the book, when playing for team. The
+// bans the player got at another team of the competition count too.
+func (b *Book) Suspension(matchKey, team, player string) (Ban, bool) {
+	r, ok := b.records[player]
+	at, in := b.at[matchKey]
+	if !ok || !in {
+		return Ban{}, false
+	}
+	n := sort.SearchInts(b.played[team], at) // index of matchKey in the matches of team
+	for i, start := range b.schedule(r, team) {
+		if start <= n && n < start+r.Bans[i].Matches {
+			return r.Bans[i], true
+		}
+	}
+	return Ban{}, false
+}
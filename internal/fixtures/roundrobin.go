@@ -0,0 +1,62 @@
+// Package fixtures generates league schedules.
+package fixtures
+
+// Pairing is one fixture of a round: Home plays Away at home.
+type Pairing struct {
+	Home string
+	Away string
+}
+
+// DoubleRoundRobin schedules every team against every other team twice, once
+// at home and once away, with the circle method: one team stays fixed while
+// the others rotate one place per round. The second half mirrors the first
+// with home and away swapped. Home and away alternate round to round except
+// for the unavoidable breaks (3n-6 for n teams). With an odd number of teams
+// one team sits out each round.
+func DoubleRoundRobin(teams []string) [][]Pairing {
+	n := len(teams)
+	if n < 2 {
+		return nil
+	}
+	slots := append([]string{}, teams...)
+	if n%2 == 1 {
+		slots = append(slots, "") // bye
+		n++
+	}
+
+	m := n - 1 // slots[m] is the fixed team
+	first := make([][]Pairing, 0, m)
+	for r := 0; r < m; r++ {
+		round := make([]Pairing, 0, n/2)
+		add := func(home, away string) {
+			if home != "" && away != "" {
+				round = append(round, Pairing{Home: home, Away: away})
+			}
+		}
+
+		if r%2 == 0 {
+			add(slots[r], slots[m])
+		} else {
+			add(slots[m], slots[r])
+		}
+		for k := 1; k < n/2; k++ {
+			a, b := slots[(r+k)%m], slots[(r-k+m)%m]
+			if k%2 == 1 {
+				add(a, b)
+			} else {
+				add(b, a)
+			}
+		}
+		first = append(first, round)
+	}
+
+	out := append([][]Pairing{}, first...)
+	for _, round := range first {
+		mirrored := make([]Pairing, len(round))
+		for i, p := range round {
+			mirrored[i] = Pairing{Home: p.Away, Away: p.Home}
+		}
+		out = append(out, mirrored)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// DefaultSpacing is the number of days between matchdays.
+const DefaultSpacing = 7
+
+// Request describes the schedule of one league season.
+type Request struct {
+	League    string      `json:"league"`
+	Season    string      `json:"season,omitempty"` // default: the league season covering Start
+	Start     time.Time   `json:"start"`            // kick-off of matchday 1
+	Spacing   int         `json:"spacingDays"`      // days between matchdays
+	Blackouts []time.Time `json:"blackouts,omitempty"`
+}
+
+// Schedule is a generated fixture list, one scheduled match per fixture.
+type Schedule struct {
+	League    string         `json:"league"`
+	Season    string         `json:"season,omitempty"`
+	Teams     []string       `json:"teams"`
+	Matchdays int            `json:"matchdays"`
+	Count     int            `json:"count"`
+	Fixtures  []models.Match `json:"fixtures"`
+}
+
+// Plan builds the double round-robin of req for the league's teams (the
+// season's teams when there is a season). Nothing is stored. msg explains
+// why the schedule cannot be made.
+func Plan(ctx context.Context, teams repository.TeamStore, seasons repository.SeasonStore, matches repository.MatchStore, req Request) (sched Schedule, msg string, err error) {
+	if req.Spacing == 0 {
+		req.Spacing = DefaultSpacing
+	}
+	if req.Spacing < 1 {
+		return sched, "spacingDays must be >= 1", nil
+	}
+	req.Start = req.Start.UTC()
+
+	leagueTeams, err := teams.ListByLeague(ctx, req.League)
+	if err != nil {
+		return sched, "", err
+	}
+	if len(leagueTeams) == 0 {
+		return sched, "unknown league", nil
+	}
+
+	season, msg, err := seasonOf(ctx, seasons, req)
+	if err != nil || msg != "" {
+		return sched, msg, err
+	}
+
+	var codes []string
+	for _, t := range leagueTeams {
+		if season == nil || season.HasTeam(t.Code) {
+			codes = append(codes, t.Code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) < 2 {
+		return sched, "a schedule needs at least 2 teams", nil
+	}
+
+	sched = Schedule{League: req.League, Teams: codes}
+	if season != nil {
+		sched.Season = season.ID
+		existing, err := matches.List(ctx, season.ID)
+		if err != nil {
+			return sched, "", err
+		}
+		if len(existing) > 0 {
+			return sched, fmt.Sprintf("season %s already has %d matches", season.ID, len(existing)), nil
+		}
+	}
+
+	blackout := make(map[string]bool, len(req.Blackouts))
+	for _, d := range req.Blackouts {
+		blackout[d.UTC().Format(time.DateOnly)] = true
+	}
+
+	rounds := DoubleRoundRobin(codes)
+	sched.Matchdays = len(rounds)
+	sched.Fixtures = make([]models.Match, 0, len(rounds)*len(codes)/2)
+	day := req.Start
+	for i, round := range rounds {
+		// a blacked-out matchday moves to the next free day; later ones follow on
+		for tries := 0; blackout[day.Format(time.DateOnly)]; tries++ {
+			if tries > 366 {
+				return sched, "blackouts leave no free day", nil
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		if season != nil && !season.Contains(day) {
+			return sched, fmt.Sprintf("matchday %d on %s falls outside season %s", i+1, day.Format(time.DateOnly), season.ID), nil
+		}
+		for _, p := range round {
+			sched.Fixtures = append(sched.Fixtures, models.Match{
+				MatchKey: MatchKey(p.Home, p.Away, day),
+				Season:   sched.Season,
+				Matchday: i + 1,
+				DateTime: day,
+				HomeCode: p.Home,
+				AwayCode: p.Away,
+				Status:   models.Scheduled,
+				Events:   []models.MatchEvent{},
+			})
+		}
+		day = day.AddDate(0, 0, req.Spacing)
+	}
+	sched.Count = len(sched.Fixtures)
+	return sched, "", nil
+}
+
+// MatchKey is the key of a generated fixture. It depends only on the teams and
+// the kick-off, so generating the same fixture twice gives the same key.
+func MatchKey(home, away string, dt time.Time) string {
+	return home + "-" + away + "-" + dt.UTC().Format("20060102-150405")
+}
+
+// seasonOf is the season req schedules: the requested one, else the league's
+// season covering the start. nil for leagues without seasons.
+func seasonOf(ctx context.Context, seasons repository.SeasonStore, req Request) (*models.Season, string, error) {
+	if req.Season != "" {
+		s, found, err := seasons.Find(ctx, req.Season)
+		if err != nil {
+			return nil, "", err
+		}
+		if !found || s.League != req.League {
+			return nil, "season not found", nil
+		}
+		if s.Status != models.SeasonOpen {
+			return nil, "season " + s.ID + " is closed", nil
+		}
+		return &s, "", nil
+	}
+
+	list, err := seasons.List(ctx, req.League)
+	if err != nil || len(list) == 0 {
+		return nil, "", err
+	}
+	for _, s := range list {
+		if s.Contains(req.Start) {
+			if s.Status != models.SeasonOpen {
+				return nil, "season " + s.ID + " is closed", nil
+			}
+			return &s, "", nil
+		}
+	}
+	return nil, "no " + req.League + " season covers start; pass season", nil
+}
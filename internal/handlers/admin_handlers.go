@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/projection"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/scores"
+)
+
+type AdminHandler struct {
+	matches   repository.MatchStore
+	events    repository.EventStore
+	projector *projection.Projector
+}
+
+func NewAdminHandler(matches repository.MatchStore, events repository.EventStore, projector *projection.Projector) *AdminHandler {
+	return &AdminHandler{matches: matches, events: events, projector: projector}
+}
+
+// POST /admin/scores/reconcile?repair=true
+// Reports matches whose stored score disagrees with the goal events; repair=true fixes them.
+func (h *AdminHandler) ReconcileScores(w http.ResponseWriter, r *http.Request) {
+	repair := r.URL.Query().Get("repair") == "true"
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	rep, err := scores.Reconcile(ctx, h.matches, h.events, repair)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if err := h.projector.ApplyKeys(ctx, rep.RepairedKeys()...); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "scores repaired, updating standings failed"})
+		return
+	}
+	writeJSON(w, 200, rep)
+}
+
+// POST /admin/standings/rebuild?league=EPL&season=EPL-2025-26
+// Recomputes the standings projection from the matches: every league season,
+// those of league, or one season of league (season=none: matches without a season).
+func (h *AdminHandler) RebuildStandings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	league := strings.TrimSpace(q.Get("league"))
+	season := strings.TrimSpace(q.Get("season"))
+	if season != "" && league == "" {
+		writeJSON(w, 400, map[string]string{"error": "season requires league"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if season != "" {
+		if season == "none" {
+			season = ""
+		}
+		s, err := h.projector.Rebuild(ctx, league, season)
+		if err != nil {
+			writeSeasonError(w, err)
+			return
+		}
+		writeJSON(w, 200, map[string]any{"rebuilt": []string{s.ID}, "count": 1})
+		return
+	}
+
+	ids, err := h.projector.RebuildAll(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	writeJSON(w, 200, map[string]any{"rebuilt": ids, "count": len(ids)})
+}
+
+// POST /admin/standings/check?league=EPL&repair=true
+// Compares the standings projection with a full recomputation from the
+// matches; repair=true rebuilds the standings that disagree.
+func (h *AdminHandler) CheckStandings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	rep, err := h.projector.Check(ctx, strings.TrimSpace(q.Get("league")), q.Get("repair") == "true")
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, 200, rep)
+}
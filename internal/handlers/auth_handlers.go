@@ -10,20 +10,17 @@ import (
 	"final-by-me/internal/auth"
 	"final-by-me/internal/models"
 	"final-by-me/internal/repository"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AuthHandler struct {
-	users     *mongo.Collection
+	users     repository.UserStore
 	jwtSecret []byte
-	teams     *repository.TeamRepo
+	teams     repository.TeamStore
 }
 
-func NewAuthHandler(db *mongo.Database, jwtSecret []byte, teams *repository.TeamRepo) *AuthHandler {
+func NewAuthHandler(users repository.UserStore, jwtSecret []byte, teams repository.TeamStore) *AuthHandler {
 	return &AuthHandler{
-		users:     db.Collection("user"),
+		users:     users,
 		jwtSecret: jwtSecret,
 		teams:     teams,
 	}
@@ -56,12 +53,12 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	// email unique
-	count, err := h.users.CountDocuments(ctx, bson.M{"email": req.Email})
+	exists, err := h.users.EmailExists(ctx, req.Email)
 	if err != nil {
 		writeJSON(w, 500, map[string]string{"error": "db error"})
 		return
 	}
-	if count > 0 {
+	if exists {
 		writeJSON(w, 409, map[string]string{"error": "email already exists"})
 		return
 	}
@@ -97,14 +94,14 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:        time.Now(),
 	}
 
-	res, err := h.users.InsertOne(ctx, u)
+	u, err = h.users.Create(ctx, u)
 	if err != nil {
 		writeJSON(w, 500, map[string]string{"error": "insert error"})
 		return
 	}
 
 	writeJSON(w, 201, map[string]any{
-		"id":               res.InsertedID,
+		"id":               u.ID,
 		"email":            u.Email,
 		"name":             u.Name,
 		"role":             u.Role,
@@ -128,8 +125,8 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
 	defer cancel()
 
-	var u models.User
-	if err := h.users.FindOne(ctx, bson.M{"email": req.Email}).Decode(&u); err != nil {
+	u, found, err := h.users.FindByEmail(ctx, req.Email)
+	if err != nil || !found {
 		writeJSON(w, 401, map[string]string{"error": "invalid credentials"})
 		return
 	}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"final-by-me/internal/calendar"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+type CalendarHandler struct {
+	matches repository.MatchStore
+	teams   repository.TeamStore
+	seasons repository.SeasonStore
+}
+
+func NewCalendarHandler(matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore) *CalendarHandler {
+	return &CalendarHandler{matches: matches, teams: teams, seasons: seasons}
+}
+
+// GET /calendar/teams/{code}.ics?season=EPL-2025-26
+// The team's matches as an iCalendar feed; season as for GET /matches.
+func (h *CalendarHandler) TeamFeed(w http.ResponseWriter, r *http.Request) {
+	code, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	team, found, err := h.teams.Find(ctx, code)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "team not found"})
+		return
+	}
+
+	h.feed(ctx, w, r, team.League, team.Name+" fixtures", func(m models.Match) bool {
+		return m.HomeCode == code || m.AwayCode == code
+	})
+}
+
+// GET /calendar/leagues/{league}.ics?season=EPL-2025-26
+// The league's matches as an iCalendar feed; season as for GET /matches.
+func (h *CalendarHandler) LeagueFeed(w http.ResponseWriter, r *http.Request) {
+	league, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	league = strings.TrimSpace(league)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	teams, err := h.teams.ListByLeague(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if len(teams) == 0 {
+		writeJSON(w, 404, map[string]string{"error": "league not found"})
+		return
+	}
+	seasons, err := h.seasons.List(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	inLeague := make(map[string]bool, len(teams)+len(seasons))
+	for _, t := range teams {
+		inLeague[t.Code] = true
+	}
+	for _, s := range seasons {
+		inLeague[s.ID] = true
+	}
+
+	// season-less matches belong to their home team's league
+	h.feed(ctx, w, r, league, league+" fixtures", func(m models.Match) bool {
+		if m.Season != "" {
+			return inLeague[m.Season]
+		}
+		return inLeague[m.HomeCode]
+	})
+}
+
+// feed writes the matches of league's season scope that keep accepts. The
+// ETag is a hash of the feed, so a client revalidating with If-None-Match
+// gets 304 until a match in it changes.
+func (h *CalendarHandler) feed(ctx context.Context, w http.ResponseWriter, r *http.Request, league, name string, keep func(models.Match) bool) {
+	scope, err := resolveSeason(ctx, h.seasons, h.teams, league, strings.TrimSpace(r.URL.Query().Get("season")))
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	list, err := h.matches.List(ctx, scope.IDs...)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	all, err := h.teams.List(ctx)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+
+	f := calendar.Feed{Name: name, Teams: make(map[string]models.Team, len(all))}
+	for _, t := range all {
+		f.Teams[t.Code] = t
+	}
+	for _, m := range list {
+		if keep(m) {
+			f.Matches = append(f.Matches, m)
+		}
+	}
+	sort.Slice(f.Matches, func(i, j int) bool {
+		if !f.Matches[i].DateTime.Equal(f.Matches[j].DateTime) {
+			return f.Matches[i].DateTime.Before(f.Matches[j].DateTime)
+		}
+		return f.Matches[i].MatchKey < f.Matches[j].MatchKey
+	})
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "render error"})
+		return
+	}
+	sum := sha256.Sum256(buf.Bytes())
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	// ServeContent answers If-None-Match with 304 and handles HEAD
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(buf.Bytes()))
+}
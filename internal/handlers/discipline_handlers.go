@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"final-by-me/internal/discipline"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+type DisciplineHandler struct {
+	matches repository.MatchStore
+	teams   repository.TeamStore
+	seasons repository.SeasonStore
+	rules   repository.RulesStore
+}
+
+func NewDisciplineHandler(matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore, rules repository.RulesStore) *DisciplineHandler {
+	return &DisciplineHandler{matches: matches, teams: teams, seasons: seasons, rules: rules}
+}
+
+// disciplineRow is a player's record in one competition: a season id, or the
+// league for matches without a season.
+type disciplineRow struct {
+	Competition string `json:"competition"`
+	discipline.Record
+}
+
+// GET /discipline?league=EPL&season=EPL-2025-26&team=ARS&suspended=true
+// Cards and bans per player and competition; season as for GET /matches.
+// Bans follow the discipline of the league rules.
+func (h *DisciplineHandler) GetDiscipline(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	p := r.URL.Query()
+	q := models.MatchQuery{Statuses: discipline.Statuses, Events: true}
+	scope, msg, err := leagueQuery(ctx, h.seasons, h.teams, p, &q)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+	team := strings.ToUpper(strings.TrimSpace(p.Get("team")))
+	onlySuspended := p.Get("suspended") == "true"
+
+	list, _, err := h.matches.Query(ctx, q)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	all, err := h.teams.List(ctx)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	leagueOf := make(map[string]string, len(all))
+	for _, t := range all {
+		leagueOf[t.Code] = t.League
+	}
+
+	// one book per competition, with the rules of its league
+	byComp := map[string][]models.Match{}
+	for _, m := range list {
+		comp := m.Season
+		if comp == "" {
+			comp = leagueOf[m.HomeCode]
+		}
+		byComp[comp] = append(byComp[comp], m)
+	}
+	comps := make([]string, 0, len(byComp))
+	for c := range byComp {
+		comps = append(comps, c)
+	}
+	sort.Strings(comps)
+
+	rows := []disciplineRow{}
+	for _, c := range comps {
+		league, err := matchLeague(ctx, h.seasons, h.teams, byComp[c][0])
+		if err != nil {
+			writeJSON(w, 500, map[string]string{"error": "db error"})
+			return
+		}
+		rules, err := leagueRules(ctx, h.rules, league)
+		if err != nil {
+			writeJSON(w, 500, map[string]string{"error": "db error"})
+			return
+		}
+		for _, rec := range discipline.Build(byComp[c], rules.DisciplineRules()).Records() {
+			if (team != "" && rec.TeamCode != team) || (onlySuspended && !rec.Suspended) {
+				continue
+			}
+			rows = append(rows, disciplineRow{Competition: c, Record: rec})
+		}
+	}
+	writeJSON(w, 200, map[string]any{"season": scope.ID(), "players": rows, "count": len(rows)})
+}
+
+// matchLeague is the league m counts in, as for the standings: its season's,
+// else its home team's.
+func matchLeague(ctx context.Context, seasons repository.SeasonStore, teams repository.TeamStore, m models.Match) (string, error) {
+	if m.Season != "" {
+		s, found, err := seasons.Find(ctx, m.Season)
+		if err != nil || found {
+			return s.League, err
+		}
+	}
+	t, _, err := teams.Find(ctx, m.HomeCode)
+	return t.League, err
+}
+
+// checkSuspensions rejects an event of m naming a player who is suspended
+// for m. Bans are those of m's competition, whichever team of it the player
+// got them at. msg explains a rejection.
+func checkSuspensions(ctx context.Context, matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore, rules repository.RulesStore, m models.Match, e models.MatchEvent) (msg string, err error) {
+	league, err := matchLeague(ctx, seasons, teams, m)
+	if err != nil {
+		return "", err
+	}
+	lr, err := leagueRules(ctx, rules, league)
+	if err != nil {
+		return "", err
+	}
+
+	q := models.MatchQuery{Seasons: []string{m.Season}, Statuses: discipline.Statuses, Events: true}
+	if m.Season == "" {
+		// matches without a season belong to their home team's league
+		inLeague, err := teams.ListByLeague(ctx, league)
+		if err != nil {
+			return "", err
+		}
+		q.NoSeasonHome = []string{}
+		for _, t := range inLeague {
+			q.NoSeasonHome = append(q.NoSeasonHome, t.Code)
+		}
+	}
+	list, _, err := matches.Query(ctx, q)
+	if err != nil {
+		return "", err
+	}
+	listed := false
+	for _, o := range list {
+		listed = listed || o.MatchKey == m.MatchKey
+	}
+	if !listed {
+		list = append(list, m) // not kicked off yet
+	}
+	book := discipline.Build(list, lr.DisciplineRules())
+
+	for _, p := range [][2]string{
+		{e.PlayerID, e.Player},
+		{e.PlayerOutID, e.PlayerOut},
+		{e.PlayerInID, e.PlayerIn},
+		{e.AssistID, e.Assist},
+	} {
+		if p[0] == "" && p[1] == "" {
+			continue
+		}
+		if ban, ok := book.Suspension(m.MatchKey, e.TeamCode, models.PlayerKey(p[0], p[1], e.TeamCode)); ok {
+			return fmt.Sprintf("%s is suspended for this match (%s in %s, %d matches)", p[1], ban.Reason, ban.MatchKey, ban.Matches), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+// GET /matches/{key}
+// The match with both teams, its events grouped by period, per-team event
+// counts and the previous meeting of the two teams that has a result.
+func (h *MatchMongoHandler) GetMatch(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	if key == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+
+	home, _, err := h.teams.Find(ctx, m.HomeCode)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	away, _, err := h.teams.Find(ctx, m.AwayCode)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+
+	prev, _, err := h.matches.Query(ctx, models.MatchQuery{
+		Team:     m.HomeCode,
+		Opponent: m.AwayCode,
+		Statuses: []models.MatchStatus{models.Finished, models.Awarded},
+		To:       m.DateTime,
+		Sort:     models.SortDateDesc,
+		Limit:    1,
+	})
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	var previous *listedMatch
+	if len(prev) > 0 {
+		previous = &listedMatch{Match: prev[0]}
+	}
+
+	if m.Events == nil {
+		m.Events = []models.MatchEvent{}
+	}
+	writeJSON(w, 200, map[string]any{
+		"match":           m,
+		"home":            teamOrCode(home, m.HomeCode),
+		"away":            teamOrCode(away, m.AwayCode),
+		"timeline":        m.Timeline(),
+		"counts":          map[string]models.EventCounts{"home": m.Counts(m.HomeCode), "away": m.Counts(m.AwayCode)},
+		"previousMeeting": previous,
+	})
+}
+
+// teamOrCode is t, or a team with just its code when it is not stored (any more).
+func teamOrCode(t models.Team, code string) models.Team {
+	if t.Code == "" {
+		t.Code = code
+	}
+	return t
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/live"
+	"final-by-me/internal/middleware"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/squad"
+)
+
+// actorID is the user id set by AuthJWT ("" on public routes)
+func actorID(r *http.Request) string {
+	id, _ := r.Context().Value(middleware.CtxUserID).(string)
+	return id
+}
+
+// PUT /matches/{key}/events/{id}
+// Replaces an event; the score moves by the difference in the same update.
+// Legacy events without player ids may keep free-text names. Players
+// suspended for the match are rejected with 409. Events of a finished match
+// are corrected with ?reason=, which is kept in the match's corrections.
+// Matches of a closed season are frozen (409).
+func (h *MatchMongoHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	id := strings.TrimSpace(r.PathValue("id"))
+	if key == "" || id == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key or event id"})
+		return
+	}
+
+	var req models.MatchEvent
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if msg := req.Normalize(); msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+	if req.TeamCode != m.HomeCode && req.TeamCode != m.AwayCode {
+		writeJSON(w, 400, map[string]string{"error": "teamCode is not playing in this match"})
+		return
+	}
+
+	old, ok := m.FindEvent(id)
+	if !ok {
+		writeJSON(w, 404, map[string]string{"error": "event not found"})
+		return
+	}
+	correction, ok := eventCorrection(w, r, m, old, models.CorrectionUpdated)
+	if !ok || !h.seasonOpen(ctx, w, m) {
+		return
+	}
+	// legacy events without player ids may keep their free-text names
+	legacy := old.PlayerID == "" && old.PlayerOutID == "" && old.PlayerInID == "" && old.AssistID == ""
+	if msg, err := squad.ResolveEvent(ctx, h.players, &req, &old, legacy); err != nil || msg != "" {
+		writeResolveError(w, msg, err)
+		return
+	}
+	if msg, err := checkSuspensions(ctx, h.matches, h.teams, h.seasons, h.rules, m, req); err != nil || msg != "" {
+		writeSuspensionError(w, msg, err)
+		return
+	}
+
+	// time is checked against the period the event was recorded in
+	if err := models.CheckEventTime(old.Period, req.Minute, req.Stoppage); err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// identity is kept, content replaced
+	req.Period = old.Period
+	req.ID = old.ID
+	req.CreatedAt = old.CreatedAt
+	req.CreatedBy = old.CreatedBy
+
+	if correction != nil {
+		correction.After = &req
+	}
+	if err := h.matches.UpdateEvent(ctx, key, m, req, correction); err != nil {
+		writeEventWriteError(w, err)
+		return
+	}
+	h.publish(ctx, key, live.Update{Type: live.TypeEventUpdated, Event: &req})
+	h.logEvent("event_updated", key, "event "+id+" changed by "+actorID(r)+correctionNote(correction))
+
+	writeJSON(w, 200, map[string]any{"status": "ok", "event": req})
+}
+
+// DELETE /matches/{key}/events/{id}
+// Removes an event; a removed goal is taken off the score in the same update.
+// Events of a finished match need ?reason= (see UpdateEvent).
+func (h *MatchMongoHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	id := strings.TrimSpace(r.PathValue("id"))
+	if key == "" || id == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key or event id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+
+	old, ok := m.FindEvent(id)
+	if !ok {
+		writeJSON(w, 404, map[string]string{"error": "event not found"})
+		return
+	}
+	correction, ok := eventCorrection(w, r, m, old, models.CorrectionDeleted)
+	if !ok || !h.seasonOpen(ctx, w, m) {
+		return
+	}
+
+	if err := h.matches.DeleteEvent(ctx, key, m, id, correction); err != nil {
+		writeEventWriteError(w, err)
+		return
+	}
+	h.publish(ctx, key, live.Update{Type: live.TypeEventDeleted, Event: &old})
+	h.logEvent("event_deleted", key, "event "+id+" ("+old.Type+") deleted by "+actorID(r)+correctionNote(correction))
+
+	writeJSON(w, 200, map[string]string{"status": "deleted"})
+}
+
+// eventCorrection checks that the events of m may be changed. Scheduled and
+// live matches are edited freely (nil correction); a finished match only
+// with ?reason=, returned as the correction to record. Other statuses get 409.
+func eventCorrection(w http.ResponseWriter, r *http.Request, m models.Match, old models.MatchEvent, action string) (*models.EventCorrection, bool) {
+	switch m.Status {
+	case models.Scheduled, models.Live:
+		return nil, true
+	case models.Finished:
+		reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+		if reason == "" {
+			writeJSON(w, 409, map[string]string{"error": "the match is finished: pass ?reason= to correct its events", "status": string(m.Status)})
+			return nil, false
+		}
+		return &models.EventCorrection{
+			EventID: old.ID,
+			Action:  action,
+			Before:  old,
+			Reason:  reason,
+			At:      time.Now().UTC(),
+			By:      actorID(r),
+		}, true
+	}
+	writeJSON(w, 409, map[string]string{"error": "events of a " + string(m.Status) + " match cannot be changed", "status": string(m.Status)})
+	return nil, false
+}
+
+// seasonOpen answers 409 when m belongs to a closed season: its table is
+// archived, so its results are frozen.
+func (h *MatchMongoHandler) seasonOpen(ctx context.Context, w http.ResponseWriter, m models.Match) bool {
+	if m.Season == "" {
+		return true
+	}
+	s, found, err := h.seasons.Find(ctx, m.Season)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return false
+	}
+	if found && s.Status == models.SeasonClosed {
+		writeJSON(w, 409, map[string]string{"error": "season " + s.ID + " is closed", "season": s.ID})
+		return false
+	}
+	return true
+}
+
+func correctionNote(c *models.EventCorrection) string {
+	if c == nil {
+		return ""
+	}
+	return " (correction: " + c.Reason + ")"
+}
+
+// writeResolveError answers a failed squad.ResolveEvent.
+func writeResolveError(w http.ResponseWriter, msg string, err error) {
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, 400, map[string]string{"error": msg})
+}
+
+// writeSuspensionError answers a failed checkSuspensions.
+func writeSuspensionError(w http.ResponseWriter, msg string, err error) {
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, 409, map[string]string{"error": msg})
+}
+
+func writeEventWriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeJSON(w, 404, map[string]string{"error": "event not found"})
+	case errors.Is(err, repository.ErrConflict):
+		writeJSON(w, 409, map[string]string{"error": "match or event was changed concurrently, reload and retry"})
+	default:
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+	}
+}
+
+// logEvent queues an audit entry for the background worker without blocking the request.
+func (h *MatchMongoHandler) logEvent(typ, key, msg string) {
+	sendLog(h.events, typ, key, msg)
+}
+
+// sendLog hands an audit entry to the event worker without blocking the request.
+func sendLog(ch chan<- models.EventLog, typ, key, msg string) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- models.EventLog{Type: typ, Message: msg, MatchKey: key, CreatedAt: time.Now()}:
+	default:
+	}
+}
+
+// PATCH /matches/{key}/period
+// Body: {"period":"half_time"}. Only the next period of a live match is accepted.
+func (h *MatchMongoHandler) SetPeriod(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	if key == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key"})
+		return
+	}
+
+	var req struct {
+		Period string `json:"period"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	to := models.MatchPeriod(strings.ToLower(strings.TrimSpace(req.Period)))
+	if !to.Valid() {
+		writeJSON(w, 400, map[string]string{"error": "period must be first_half|half_time|second_half|extra_time_1|extra_time_2|penalties"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+	if m.Status != models.Live {
+		writeJSON(w, 409, map[string]string{"error": "match must be live to change period"})
+		return
+	}
+	if !m.Period.CanMoveTo(to) {
+		writeJSON(w, 409, map[string]any{
+			"error":   "illegal period transition",
+			"current": m.Period,
+			"allowed": m.Period.NextPeriods(),
+		})
+		return
+	}
+
+	change := models.PeriodChange{From: m.Period, To: to, At: time.Now().UTC(), By: actorID(r)}
+	if err := h.matches.SetPeriod(ctx, key, change); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]string{"error": "period was changed concurrently, reload and retry"})
+			return
+		}
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+		return
+	}
+	h.publish(ctx, key, live.Update{Type: live.TypePeriod, Status: m.Status})
+
+	writeJSON(w, 200, map[string]any{"status": "ok", "period": to})
+}
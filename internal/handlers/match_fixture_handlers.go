@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/fixtures"
+	"final-by-me/internal/live"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// PATCH /matches/{key}
+// Body: {"dateTime":"2025-03-01T15:00:00Z","knockout":true,"matchday":27,"reason":"TV pick"} (all optional)
+// Edits a fixture that has not kicked off; the matchKey and history are kept.
+// matchday can also be corrected afterwards, unless the season is closed (409).
+func (h *MatchMongoHandler) UpdateMatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DateTime *string `json:"dateTime"`
+		Knockout *bool   `json:"knockout"`
+		Matchday *int    `json:"matchday"`
+		Reason   string  `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if req.DateTime == nil && req.Knockout == nil && req.Matchday == nil {
+		writeJSON(w, 400, map[string]string{"error": "nothing to update: dateTime, knockout or matchday required"})
+		return
+	}
+	if req.Matchday != nil && *req.Matchday < 1 {
+		writeJSON(w, 400, map[string]string{"error": "matchday must be >= 1"})
+		return
+	}
+
+	var dt time.Time
+	if req.DateTime != nil {
+		var err error
+		if dt, err = time.Parse(time.RFC3339, strings.TrimSpace(*req.DateTime)); err != nil {
+			writeJSON(w, 400, map[string]string{"error": "dateTime must be RFC3339"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok {
+		return
+	}
+	fixtureEdit := req.DateTime != nil || req.Knockout != nil
+	if fixtureEdit && m.Status != models.Scheduled && m.Status != models.Postponed {
+		writeJSON(w, 409, map[string]string{"error": "only scheduled or postponed matches can be edited (status: " + string(m.Status) + ")"})
+		return
+	}
+
+	// everything is checked above; the changes go in one write
+	var edit models.FixtureEdit
+	if req.Matchday != nil && *req.Matchday != m.Matchday {
+		edit.Matchday = req.Matchday
+	}
+	if edit.Matchday != nil && !h.seasonOpen(ctx, w, m) {
+		return
+	}
+	if req.Knockout != nil && *req.Knockout != m.Knockout {
+		edit.Knockout = req.Knockout
+	}
+	if req.DateTime != nil && !dt.Equal(m.DateTime) {
+		edit.Reschedule = &models.Reschedule{From: m.DateTime, To: dt.UTC(), At: time.Now().UTC(), By: actorID(r), Reason: strings.TrimSpace(req.Reason)}
+	}
+	if !edit.Empty() {
+		if err := h.matches.EditFixture(ctx, m.MatchKey, m, edit); err != nil {
+			writeStatusError(w, err)
+			return
+		}
+		h.publish(ctx, m.MatchKey, live.Update{Type: live.TypeFixture, Status: m.Status})
+	}
+	if rs := edit.Reschedule; rs != nil {
+		h.logEvent("match_rescheduled", m.MatchKey, fmt.Sprintf("%s -> %s by %s", rs.From.Format(time.RFC3339), rs.To.Format(time.RFC3339), rs.By))
+	}
+
+	h.respondMatch(ctx, w, m.MatchKey)
+}
+
+// POST /matches/{key}/postpone
+// Body: {"reason":"waterlogged pitch"}
+func (h *MatchMongoHandler) Postpone(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		writeJSON(w, 400, map[string]string{"error": "reason required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok {
+		return
+	}
+	h.transition(ctx, w, r, m, models.Postponed, req.Reason)
+}
+
+// POST /matches/{key}/reschedule
+// Body: {"dateTime":"2025-03-08T15:00:00Z","reason":"new date agreed"}
+// Moves a scheduled or postponed fixture; a postponed one becomes scheduled again.
+func (h *MatchMongoHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	dt, reason, ok := decodeNewDate(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok {
+		return
+	}
+	if m.Status != models.Scheduled && m.Status != models.Postponed {
+		writeJSON(w, 409, map[string]string{"error": "only scheduled or postponed matches can be rescheduled (status: " + string(m.Status) + ")"})
+		return
+	}
+
+	now := time.Now().UTC()
+	rs := models.Reschedule{From: m.DateTime, To: dt, At: now, By: actorID(r), Reason: reason}
+	var change *models.StatusChange
+	if m.Status == models.Postponed {
+		change = &models.StatusChange{From: m.Status, To: models.Scheduled, At: now, By: rs.By, Reason: reason}
+	}
+	if err := h.matches.Reschedule(ctx, m.MatchKey, m, rs, change); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	h.publish(ctx, m.MatchKey, live.Update{Type: live.TypeFixture, Status: models.Scheduled})
+	h.logEvent("match_rescheduled", m.MatchKey, fmt.Sprintf("%s -> %s by %s", rs.From.Format(time.RFC3339), rs.To.Format(time.RFC3339), rs.By))
+	h.respondMatch(ctx, w, m.MatchKey)
+}
+
+// POST /matches/{key}/resume
+// Continues a suspended or abandoned match from where it stopped (score and events are kept).
+func (h *MatchMongoHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok {
+		return
+	}
+	if m.Status != models.Suspended && m.Status != models.Abandoned {
+		writeJSON(w, 409, map[string]string{"error": "only suspended or abandoned matches can be resumed (status: " + string(m.Status) + ")"})
+		return
+	}
+	h.transition(ctx, w, r, m, models.Live, "resumed")
+}
+
+// POST /matches/{key}/replay
+// Body: {"dateTime":"2025-03-08T15:00:00Z","reason":"floodlight failure"}
+// Schedules an abandoned match to be played again from 0-0. The abandoned
+// attempt is kept under attempts.
+func (h *MatchMongoHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	dt, reason, ok := decodeNewDate(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok {
+		return
+	}
+	if m.Status != models.Abandoned {
+		writeJSON(w, 409, map[string]string{"error": "only abandoned matches can be replayed (status: " + string(m.Status) + ")"})
+		return
+	}
+
+	now := time.Now().UTC()
+	change := models.StatusChange{From: m.Status, To: models.Scheduled, At: now, By: actorID(r), Reason: reason}
+	rs := models.Reschedule{From: m.DateTime, To: dt, At: now, By: change.By, Reason: reason}
+	if err := h.matches.Replay(ctx, m.MatchKey, m, change, rs); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	h.publish(ctx, m.MatchKey, live.Update{Type: live.TypeFixture, Status: models.Scheduled})
+	h.logEvent("match_replay", m.MatchKey, fmt.Sprintf("replay on %s by %s", dt.Format(time.RFC3339), change.By))
+	h.respondMatch(ctx, w, m.MatchKey)
+}
+
+// POST /matches/{key}/award
+// Body: {"homeGoals":3,"awayGoals":0,"reason":"ineligible player"}
+// Records a result decided by the league, also for a finished match. The
+// played score and events stay on the match; tables use the awarded score.
+func (h *MatchMongoHandler) AwardMatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		HomeGoals *int   `json:"homeGoals"`
+		AwayGoals *int   `json:"awayGoals"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.HomeGoals == nil || req.AwayGoals == nil || req.Reason == "" {
+		writeJSON(w, 400, map[string]string{"error": "homeGoals, awayGoals, reason required"})
+		return
+	}
+	if *req.HomeGoals < 0 || *req.AwayGoals < 0 {
+		writeJSON(w, 400, map[string]string{"error": "goals must be >= 0"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, ok := h.loadMatch(ctx, w, r)
+	if !ok || !h.seasonOpen(ctx, w, m) {
+		return
+	}
+
+	now := time.Now().UTC()
+	change := models.StatusChange{From: m.Status, To: models.Awarded, At: now, By: actorID(r), Reason: req.Reason}
+	award := models.Award{HomeGoals: *req.HomeGoals, AwayGoals: *req.AwayGoals, Reason: req.Reason, At: now, By: change.By}
+	if err := h.matches.Award(ctx, m.MatchKey, change, award); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	h.publish(ctx, m.MatchKey, live.Update{Type: live.TypeStatus, Status: models.Awarded})
+	h.logEvent("status_awarded", m.MatchKey, fmt.Sprintf("%s -> awarded %d-%d by %s", m.Status, award.HomeGoals, award.AwayGoals, change.By))
+	h.respondMatch(ctx, w, m.MatchKey)
+}
+
+// POST /leagues/{league}/fixtures?preview=true
+// Body: {"start":"2025-08-16T15:00:00Z","spacingDays":7,"blackouts":["2025-12-25"],"season":"EPL-2025-26"}
+// Generates a double round-robin for the league's teams (the season's teams
+// when it has seasons; season defaults to the one covering start). Matchday n
+// kicks off spacingDays after matchday n-1; a matchday on a blackout date
+// moves to the next free day. preview=true returns the fixtures without
+// storing them; otherwise they are inserted in one bulk write.
+func (h *MatchMongoHandler) GenerateFixtures(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Start     string   `json:"start"` // RFC3339
+		Spacing   int      `json:"spacingDays"`
+		Blackouts []string `json:"blackouts"` // YYYY-MM-DD
+		Season    string   `json:"season"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	start, err := time.Parse(time.RFC3339, strings.TrimSpace(req.Start))
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": "start must be RFC3339"})
+		return
+	}
+	fr := fixtures.Request{
+		League:  strings.TrimSpace(r.PathValue("league")),
+		Season:  strings.TrimSpace(req.Season),
+		Start:   start,
+		Spacing: req.Spacing,
+	}
+	for _, b := range req.Blackouts {
+		d, err := parseDay(b)
+		if err != nil {
+			writeJSON(w, 400, map[string]string{"error": "blackouts must be YYYY-MM-DD"})
+			return
+		}
+		fr.Blackouts = append(fr.Blackouts, d)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	sched, msg, err := fixtures.Plan(ctx, h.teams, h.seasons, h.matches, fr)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+	if r.URL.Query().Get("preview") == "true" {
+		writeJSON(w, 200, map[string]any{"preview": true, "schedule": sched})
+		return
+	}
+
+	n, err := h.matches.CreateMany(ctx, sched.Fixtures)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]any{"error": "some fixtures already exist", "inserted": n})
+			return
+		}
+		writeJSON(w, 500, map[string]any{"error": "create error", "inserted": n})
+		return
+	}
+	h.logEvent("fixtures_generated", "", fmt.Sprintf("%s %s: %d fixtures over %d matchdays by %s", sched.League, sched.Season, n, sched.Matchdays, actorID(r)))
+	writeJSON(w, 201, map[string]any{"preview": false, "inserted": n, "schedule": sched})
+}
+
+// loadMatch reads {key} and writes the error response when it cannot.
+func (h *MatchMongoHandler) loadMatch(ctx context.Context, w http.ResponseWriter, r *http.Request) (models.Match, bool) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	if key == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key"})
+		return models.Match{}, false
+	}
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return models.Match{}, false
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return models.Match{}, false
+	}
+	return m, true
+}
+
+// respondMatch answers with the match as stored after a write.
+func (h *MatchMongoHandler) respondMatch(ctx context.Context, w http.ResponseWriter, key string) {
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil || !found {
+		writeJSON(w, 200, map[string]string{"status": "ok"})
+		return
+	}
+	writeJSON(w, 200, m)
+}
+
+// decodeNewDate reads {"dateTime","reason"} for reschedule and replay.
+func decodeNewDate(w http.ResponseWriter, r *http.Request) (time.Time, string, bool) {
+	var req struct {
+		DateTime string `json:"dateTime"` // RFC3339
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return time.Time{}, "", false
+	}
+	req.DateTime = strings.TrimSpace(req.DateTime)
+	if req.DateTime == "" {
+		writeJSON(w, 400, map[string]string{"error": "dateTime required"})
+		return time.Time{}, "", false
+	}
+	dt, err := time.Parse(time.RFC3339, req.DateTime)
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": "dateTime must be RFC3339"})
+		return time.Time{}, "", false
+	}
+	return dt.UTC(), strings.TrimSpace(req.Reason), true
+}
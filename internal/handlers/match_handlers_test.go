@@ -0,0 +1,405 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+func TestCreateListGetMatch(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	if m.MatchKey == "" || m.Status != models.Scheduled {
+		t.Fatalf("created match = %+v", m)
+	}
+
+	var list struct {
+		Matches []models.Match `json:"matches"`
+		Count   int            `json:"count"`
+	}
+	if code := call(t, srv, "GET", "/matches?season=all&team=ARS", "", &list); code != 200 {
+		t.Fatalf("list: status %d", code)
+	}
+	if list.Count != 1 || list.Matches[0].MatchKey != m.MatchKey {
+		t.Fatalf("list = %+v", list)
+	}
+
+	var detail struct {
+		Match models.Match `json:"match"`
+		Home  models.Team  `json:"home"`
+		Away  models.Team  `json:"away"`
+	}
+	if code := call(t, srv, "GET", "/matches/"+m.MatchKey, "", &detail); code != 200 {
+		t.Fatalf("get: status %d", code)
+	}
+	if detail.Match.MatchKey != m.MatchKey || detail.Home.Code != "ARS" || detail.Away.Code != "CHE" {
+		t.Fatalf("detail = %+v", detail)
+	}
+
+	if code := call(t, srv, "GET", "/matches/NOPE", "", nil); code != 404 {
+		t.Fatalf("get unknown: status %d, want 404", code)
+	}
+}
+
+func TestCreateMatchErrors(t *testing.T) {
+	srv := newServer(t)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid JSON", `{`},
+		{"missing fields", `{"homeCode":"ARS"}`},
+		{"same teams", `{"homeCode":"ARS","awayCode":"ARS","dateTime":"2030-08-16T14:00:00Z"}`},
+		{"bad dateTime", `{"homeCode":"ARS","awayCode":"CHE","dateTime":"tomorrow"}`},
+		{"unknown team", `{"homeCode":"ARS","awayCode":"XXX","dateTime":"2030-08-16T14:00:00Z"}`},
+		{"negative matchday", `{"homeCode":"ARS","awayCode":"CHE","dateTime":"2030-08-16T14:00:00Z","matchday":-1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := call(t, srv, "POST", "/matches", tt.body, nil); code != 400 {
+				t.Fatalf("status %d, want 400", code)
+			}
+		})
+	}
+}
+
+func TestEventErrors(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	done := createMatch(t, srv, "LIV", "MCI")
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil); code != 200 {
+		t.Fatalf("kick-off: status %d", code)
+	}
+	call(t, srv, "PATCH", "/matches/"+done.MatchKey+"/status", `{"status":"live"}`, nil)
+	call(t, srv, "POST", "/matches/"+done.MatchKey+"/finalize", "", nil)
+
+	var added struct {
+		Event models.MatchEvent `json:"event"`
+	}
+	goal := `{"type":"goal","teamCode":"ARS","minute":10,"player":"Saka"}`
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", goal, &added); code != 200 {
+		t.Fatalf("add goal: status %d", code)
+	}
+
+	events := "/matches/" + m.MatchKey + "/events"
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"add: invalid JSON", "PATCH", events, `{`, 400},
+		{"add: missing minute", "PATCH", events, `{"type":"goal","teamCode":"ARS","player":"Saka"}`, 400},
+		{"add: unknown type", "PATCH", events, `{"type":"dive","teamCode":"ARS","minute":5}`, 400},
+		{"add: goal without player", "PATCH", events, `{"type":"goal","teamCode":"ARS","minute":5}`, 400},
+		{"add: team not playing", "PATCH", events, `{"type":"goal","teamCode":"LIV","minute":5,"player":"Salah"}`, 400},
+		{"add: unknown team", "PATCH", events, `{"type":"goal","teamCode":"XXX","minute":5,"player":"X"}`, 400},
+		{"add: unknown match", "PATCH", "/matches/NOPE/events", goal, 404},
+		{"add: finished match", "PATCH", "/matches/" + done.MatchKey + "/events", `{"type":"goal","teamCode":"LIV","minute":5,"player":"Salah"}`, 409},
+		{"update: unknown event", "PUT", events + "/nope", goal, 404},
+		{"update: invalid JSON", "PUT", events + "/" + added.Event.ID, `{`, 400},
+		{"update: unknown match", "PUT", "/matches/NOPE/events/" + added.Event.ID, goal, 404},
+		{"delete: unknown event", "DELETE", events + "/nope", "", 404},
+		{"delete: unknown match", "DELETE", "/matches/NOPE/events/" + added.Event.ID, "", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := call(t, srv, tt.method, tt.path, tt.body, nil); code != tt.want {
+				t.Fatalf("status %d, want %d", code, tt.want)
+			}
+		})
+	}
+
+	var got struct {
+		Match models.Match `json:"match"`
+	}
+	call(t, srv, "GET", "/matches/"+m.MatchKey, "", &got)
+	if got.Match.HomeGoals != 1 || len(got.Match.Events) != 1 {
+		t.Fatalf("rejected writes changed the match: %d goals, %d events", got.Match.HomeGoals, len(got.Match.Events))
+	}
+
+	if code := call(t, srv, "DELETE", events+"/"+added.Event.ID, "", nil); code != 200 {
+		t.Fatalf("delete: status %d", code)
+	}
+	call(t, srv, "GET", "/matches/"+m.MatchKey, "", &got)
+	if got.Match.HomeGoals != 0 || len(got.Match.Events) != 0 {
+		t.Fatalf("after delete: %d goals, %d events", got.Match.HomeGoals, len(got.Match.Events))
+	}
+}
+
+func TestCorrectFinishedMatch(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	var added struct {
+		Event models.MatchEvent `json:"event"`
+	}
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", `{"type":"goal","teamCode":"ARS","minute":10,"player":"Saka"}`, &added)
+	call(t, srv, "POST", "/matches/"+m.MatchKey+"/finalize", "", nil)
+
+	event := "/matches/" + m.MatchKey + "/events/" + added.Event.ID
+	fixed := `{"type":"goal","teamCode":"ARS","minute":12,"player":"Saka"}`
+	if code := call(t, srv, "PUT", event, fixed, nil); code != 409 {
+		t.Fatalf("update without reason: status %d, want 409", code)
+	}
+	if code := call(t, srv, "DELETE", event, "", nil); code != 409 {
+		t.Fatalf("delete without reason: status %d, want 409", code)
+	}
+	if code := call(t, srv, "PUT", event+"?reason=wrong+minute", fixed, nil); code != 200 {
+		t.Fatalf("correction: status %d", code)
+	}
+
+	var got struct {
+		Match models.Match `json:"match"`
+	}
+	call(t, srv, "GET", "/matches/"+m.MatchKey, "", &got)
+	c := got.Match.Corrections
+	if len(c) != 1 || c[0].Action != models.CorrectionUpdated || c[0].Reason != "wrong minute" || c[0].Before.Minute != 10 || c[0].After.Minute != 12 {
+		t.Fatalf("corrections = %+v", c)
+	}
+	if got.Match.HomeGoals != 1 {
+		t.Fatalf("score %d-%d after correction", got.Match.HomeGoals, got.Match.AwayGoals)
+	}
+}
+
+func TestKickOffStartsFirstHalf(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"suspended"}`, nil)
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+
+	var got struct {
+		Match models.Match `json:"match"`
+	}
+	call(t, srv, "GET", "/matches/"+m.MatchKey, "", &got)
+	if got.Match.Period != models.PeriodFirstHalf || len(got.Match.Periods) != 1 {
+		t.Fatalf("period %q, periods %+v", got.Match.Period, got.Match.Periods)
+	}
+}
+
+func TestAwardFinishedMatch(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	call(t, srv, "POST", "/matches/"+m.MatchKey+"/finalize", "", nil)
+
+	var got models.Match
+	if code := call(t, srv, "POST", "/matches/"+m.MatchKey+"/award", `{"homeGoals":0,"awayGoals":3,"reason":"ineligible player"}`, &got); code != 200 {
+		t.Fatalf("award: status %d", code)
+	}
+	if got.Status != models.Awarded || got.Award == nil || got.Award.AwayGoals != 3 {
+		t.Fatalf("awarded match = %+v", got)
+	}
+}
+
+func TestUpdateMatchIsAllOrNothing(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey, `{"matchday":3,"knockout":true,"dateTime":"soon"}`, nil); code != 400 {
+		t.Fatalf("bad dateTime: status %d, want 400", code)
+	}
+	var got models.Match
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey, `{"matchday":3,"knockout":true,"dateTime":"2030-08-17T14:00:00Z"}`, &got); code != 200 {
+		t.Fatalf("update: status %d", code)
+	}
+	if got.Matchday != 3 || !got.Knockout || got.DateTime.Day() != 17 || len(got.Reschedules) != 1 {
+		t.Fatalf("updated match = %+v", got)
+	}
+}
+
+func TestClosedSeasonIsFrozen(t *testing.T) {
+	srv, stores := newServerStores(t)
+	seasons := stores.seasons
+	ctx := context.Background()
+	season := models.Season{
+		ID: "EPL-2030-31", League: "EPL", Name: "2030-31",
+		StartDate: time.Date(2030, 8, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2031, 5, 31, 0, 0, 0, 0, time.UTC),
+		Teams:     []string{"ARS", "CHE"},
+		Status:    models.SeasonOpen,
+	}
+	if err := seasons.Create(ctx, season); err != nil {
+		t.Fatal(err)
+	}
+	m := createMatch(t, srv, "ARS", "CHE")
+	if m.Season != season.ID {
+		t.Fatalf("match season %q, want %q", m.Season, season.ID)
+	}
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	var added struct {
+		Event models.MatchEvent `json:"event"`
+	}
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", `{"type":"goal","teamCode":"ARS","minute":10,"player":"Saka"}`, &added)
+	call(t, srv, "POST", "/matches/"+m.MatchKey+"/finalize", "", nil)
+	if err := seasons.Close(ctx, season.ID, time.Now(), "test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	event := "/matches/" + m.MatchKey + "/events/" + added.Event.ID + "?reason=late+fix"
+	if code := call(t, srv, "PUT", event, `{"type":"goal","teamCode":"ARS","minute":12,"player":"Saka"}`, nil); code != 409 {
+		t.Fatalf("update: status %d, want 409", code)
+	}
+	if code := call(t, srv, "DELETE", event, "", nil); code != 409 {
+		t.Fatalf("delete: status %d, want 409", code)
+	}
+	if code := call(t, srv, "POST", "/matches/"+m.MatchKey+"/award", `{"homeGoals":0,"awayGoals":3,"reason":"late"}`, nil); code != 409 {
+		t.Fatalf("award: status %d, want 409", code)
+	}
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey, `{"matchday":5}`, nil); code != 409 {
+		t.Fatalf("matchday: status %d, want 409", code)
+	}
+}
+
+func TestScoreOnlyKeepsScore(t *testing.T) {
+	srv := newServer(t)
+	row := `[{"dateTime":"2024-01-10T15:00:00Z","homeCode":"ARS","awayCode":"CHE","homeGoals":2,"awayGoals":1,
+		"events":[{"type":"card","cardColor":"yellow","teamCode":"ARS","minute":30,"player":"Rice"}]}]`
+	var rep struct {
+		Results []struct {
+			MatchKey string `json:"matchKey"`
+			Status   string `json:"status"`
+		} `json:"results"`
+	}
+	if code := call(t, srv, "POST", "/matches/import", row, &rep); code != 200 || rep.Results[0].Status != "created" {
+		t.Fatalf("import: status %d, %+v", code, rep)
+	}
+	key := rep.Results[0].MatchKey
+
+	var got struct {
+		Match models.Match `json:"match"`
+	}
+	call(t, srv, "GET", "/matches/"+key, "", &got)
+	if !got.Match.ScoreOnly || len(got.Match.Events) != 1 {
+		t.Fatalf("imported match = %+v", got.Match)
+	}
+	if code := call(t, srv, "DELETE", "/matches/"+key+"/events/"+got.Match.Events[0].ID+"?reason=wrong+player", "", nil); code != 200 {
+		t.Fatalf("delete: status %d", code)
+	}
+	call(t, srv, "GET", "/matches/"+key, "", &got)
+	if got.Match.HomeGoals != 2 || got.Match.AwayGoals != 1 {
+		t.Fatalf("score %d-%d after editing a score-only match, want 2-1", got.Match.HomeGoals, got.Match.AwayGoals)
+	}
+}
+
+func TestEditEventAfterTransfer(t *testing.T) {
+	srv, stores := newServerStores(t)
+	ctx := context.Background()
+	saka := models.Player{ID: "p7", Name: "Bukayo Saka", TeamCode: "ARS", Number: 7}
+	if err := stores.players.Create(ctx, saka); err != nil {
+		t.Fatal(err)
+	}
+	m := createMatch(t, srv, "ARS", "CHE")
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	var added struct {
+		Event models.MatchEvent `json:"event"`
+	}
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", `{"type":"goal","teamCode":"ARS","minute":10,"playerId":"p7"}`, &added); code != 200 {
+		t.Fatalf("add goal: status %d", code)
+	}
+
+	saka.TeamCode = "LIV"
+	if err := stores.players.Update(ctx, saka); err != nil {
+		t.Fatal(err)
+	}
+	event := "/matches/" + m.MatchKey + "/events/" + added.Event.ID
+	if code := call(t, srv, "PUT", event, `{"type":"goal","teamCode":"ARS","minute":11,"playerId":"p7"}`, nil); code != 200 {
+		t.Fatalf("edit minute after transfer: status %d", code)
+	}
+	if code := call(t, srv, "PUT", event, `{"type":"goal","teamCode":"CHE","minute":11,"playerId":"p7"}`, nil); code != 400 {
+		t.Fatalf("move to another team: status %d, want 400", code)
+	}
+}
+
+func TestBanFollowsPlayerInCompetition(t *testing.T) {
+	srv, stores := newServerStores(t)
+	ctx := context.Background()
+	saka := models.Player{ID: "p7", Name: "Bukayo Saka", TeamCode: "ARS", Number: 7}
+	if err := stores.players.Create(ctx, saka); err != nil {
+		t.Fatal(err)
+	}
+	play := func(home, away, dt, event string) models.Match {
+		t.Helper()
+		var m models.Match
+		if code := call(t, srv, "POST", "/matches", `{"homeCode":"`+home+`","awayCode":"`+away+`","dateTime":"`+dt+`"}`, &m); code != 201 {
+			t.Fatalf("create %s-%s: status %d", home, away, code)
+		}
+		call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+		if event != "" {
+			if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", event, nil); code != 200 {
+				t.Fatalf("event in %s-%s: status %d", home, away, code)
+			}
+		}
+		return m
+	}
+	red := `{"type":"card","teamCode":"ARS","minute":30,"playerId":"p7","cardColor":"red"}`
+	play("ARS", "CHE", "2030-08-16T14:00:00Z", red)
+
+	saka.TeamCode = "LIV"
+	if err := stores.players.Update(ctx, saka); err != nil {
+		t.Fatal(err)
+	}
+	m := play("LIV", "MCI", "2030-08-23T14:00:00Z", "")
+	goal := `{"type":"goal","teamCode":"LIV","minute":10,"playerId":"p7"}`
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", goal, nil); code != 409 {
+		t.Fatalf("goal by a player banned at a former team: status %d, want 409", code)
+	}
+
+	// the ban is for EPL matches only
+	saka.TeamCode = "AST"
+	if err := stores.players.Update(ctx, saka); err != nil {
+		t.Fatal(err)
+	}
+	m = play("AST", "KAI", "2030-08-24T14:00:00Z", "")
+	goal = `{"type":"goal","teamCode":"AST","minute":10,"playerId":"p7"}`
+	if code := call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/events", goal, nil); code != 200 {
+		t.Fatalf("goal in another league: status %d, want 200", code)
+	}
+}
+
+func TestScorersCountMatchesAndMinutes(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+	events := "/matches/" + m.MatchKey + "/events"
+	call(t, srv, "PATCH", "/matches/"+m.MatchKey+"/status", `{"status":"live"}`, nil)
+	for _, e := range []string{
+		`{"type":"goal","teamCode":"ARS","minute":10,"player":"Saka"}`,
+		`{"type":"goal","teamCode":"ARS","minute":20,"player":"Havertz","goalType":"penalty"}`,
+		`{"type":"sub","teamCode":"ARS","minute":30,"playerOut":"Saka","playerIn":"Martinelli"}`,
+	} {
+		if code := call(t, srv, "PATCH", events, e, nil); code != 200 {
+			t.Fatalf("add %s: status %d", e, code)
+		}
+	}
+	call(t, srv, "POST", "/matches/"+m.MatchKey+"/finalize", "", nil)
+
+	var got struct {
+		Scorers []struct {
+			Player         string  `json:"player"`
+			Goals          int     `json:"goals"`
+			Matches        int     `json:"matches"`
+			MinutesPerGoal float64 `json:"minutesPerGoal"`
+		} `json:"scorers"`
+	}
+	if code := call(t, srv, "GET", "/stats/scorers", "", &got); code != 200 {
+		t.Fatalf("scorers: status %d", code)
+	}
+	want := map[string]float64{"Havertz": 90, "Saka": 30} // Saka was subbed off
+	if len(got.Scorers) != len(want) {
+		t.Fatalf("scorers = %+v", got.Scorers)
+	}
+	for _, e := range got.Scorers {
+		if e.Goals != 1 || e.Matches != 1 || e.MinutesPerGoal != want[e.Player] {
+			t.Fatalf("%s: %+v, want 1 goal in 1 match, %v minutes per goal", e.Player, e, want[e.Player])
+		}
+	}
+
+	call(t, srv, "GET", "/stats/scorers?penalties=false", "", &got)
+	if len(got.Scorers) != 1 || got.Scorers[0].Player != "Saka" {
+		t.Fatalf("scorers without penalties = %+v", got.Scorers)
+	}
+}
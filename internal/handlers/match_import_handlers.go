@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/importer"
+	"final-by-me/internal/repository"
+)
+
+// maxImportBytes bounds the body of an import (a few seasons of a league).
+const maxImportBytes = 10 << 20
+
+// POST /matches/import?format=csv&tz=Europe/London&dryRun=true
+// Body: CSV in the football-data layout (Date,Time,HomeTeam,AwayTeam,FTHG,FTAG)
+// or a JSON array of {"dateTime","homeCode","awayCode","homeGoals","awayGoals",
+// "season","matchday","knockout","events"}. Teams may be given by code or name.
+// format defaults from Content-Type; tz is the zone of CSV dates (default UTC).
+// Invalid rows are reported and skipped; rows imported before are left alone,
+// except scheduled fixtures without events, which get the imported result.
+func (h *MatchMongoHandler) ImportMatches(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dryRun := q.Get("dryRun") == "true"
+
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format == "" {
+		format = "json"
+		if strings.Contains(r.Header.Get("Content-Type"), "csv") {
+			format = "csv"
+		}
+	}
+	loc := time.UTC
+	if tz := strings.TrimSpace(q.Get("tz")); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			writeJSON(w, 400, map[string]string{"error": "unknown tz"})
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var rows []importer.Row
+	var err error
+	switch format {
+	case "csv":
+		rows, err = importer.ParseCSV(body, loc)
+	case "json":
+		rows, err = importer.ParseJSON(body)
+	default:
+		writeJSON(w, 400, map[string]string{"error": "format must be csv|json"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		writeJSON(w, 400, map[string]string{"error": "nothing to import"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	rep, err := importer.Import(ctx, h.teams, h.seasons, h.players, h.matches, rows, dryRun, actorID(r))
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]string{"error": "another import stored some of these matches; run it again"})
+			return
+		}
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if dryRun {
+		writeJSON(w, 200, rep)
+		return
+	}
+
+	for _, m := range rep.Matches {
+		if err := h.projector.Apply(ctx, m); err != nil {
+			h.logEvent("standings_error", m.MatchKey, err.Error())
+		}
+	}
+	h.logEvent("matches_imported", "", fmt.Sprintf("%d created, %d updated, %d existing, %d invalid by %s", rep.Created, rep.Updated, rep.Existing, rep.Invalid, actorID(r)))
+	writeJSON(w, 200, rep)
+}
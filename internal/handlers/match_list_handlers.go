@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+const (
+	defaultMatchLimit = 100
+	maxMatchLimit     = 500
+)
+
+// listedMatch is a match in a listing: events only with include=events.
+type listedMatch struct {
+	models.Match
+	Events []models.MatchEvent `json:"events,omitempty"`
+}
+
+// GET /matches?league=EPL&season=EPL-2025-26&team=ARS&opponent=CHE&status=scheduled,live
+//
+//	&from=2025-08-01&to=2025-08-31&matchday=3&sort=-dateTime&limit=50&cursor=...&include=events
+//
+// season defaults to the current season of league (or of every league);
+// season=all lists every season. from/to take a day (to inclusive) or RFC3339
+// (to exclusive). sort: dateTime | -dateTime | matchday | -matchday. The next
+// page is at cursor=nextCursor, which is absent on the last page.
+func (h *MatchMongoHandler) ListMatches(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	q, scope, msg, err := h.matchQuery(ctx, r)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+
+	page, next, err := h.matches.Query(ctx, q)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	list := make([]listedMatch, len(page))
+	for i, m := range page {
+		list[i] = listedMatch{Match: m, Events: m.Events}
+		if q.Events && m.Events == nil {
+			list[i].Events = []models.MatchEvent{}
+		}
+	}
+
+	resp := map[string]any{"matches": list, "count": len(list), "season": scope.ID()}
+	if next != nil {
+		resp["nextCursor"] = next.Encode()
+	}
+	writeJSON(w, 200, resp)
+}
+
+// matchQuery reads the listing parameters. msg explains a bad parameter.
+func (h *MatchMongoHandler) matchQuery(ctx context.Context, r *http.Request) (q models.MatchQuery, scope seasonScope, msg string, err error) {
+	p := r.URL.Query()
+	if scope, msg, err = leagueQuery(ctx, h.seasons, h.teams, p, &q); err != nil || msg != "" {
+		return q, scope, msg, err
+	}
+
+	if v := strings.TrimSpace(p.Get("team")); v != "" {
+		q.Team = strings.ToUpper(v)
+		ok, err := h.teams.Exists(ctx, q.Team)
+		if err != nil {
+			return q, scope, "", err
+		}
+		if !ok {
+			return q, scope, "unknown team", nil
+		}
+	}
+	if v := strings.TrimSpace(p.Get("opponent")); v != "" {
+		if q.Team == "" {
+			return q, scope, "opponent requires team", nil
+		}
+		q.Opponent = strings.ToUpper(v)
+	}
+	if v := strings.TrimSpace(p.Get("status")); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			st := models.MatchStatus(strings.ToLower(strings.TrimSpace(s)))
+			if !st.Valid() {
+				return q, scope, "status must be a comma-separated list of scheduled|live|finished|postponed|suspended|abandoned|cancelled|awarded", nil
+			}
+			q.Statuses = append(q.Statuses, st)
+		}
+	}
+	if v := strings.TrimSpace(p.Get("from")); v != "" {
+		if q.From, err = parseDay(v); err != nil {
+			return q, scope, "from must be YYYY-MM-DD or RFC3339", nil
+		}
+	}
+	if v := strings.TrimSpace(p.Get("to")); v != "" {
+		if q.To, err = parseDay(v); err != nil {
+			return q, scope, "to must be YYYY-MM-DD or RFC3339", nil
+		}
+		if len(v) == len(time.DateOnly) {
+			q.To = q.To.AddDate(0, 0, 1) // the whole day
+		}
+	}
+	if v := strings.TrimSpace(p.Get("matchday")); v != "" {
+		if q.Matchday, err = strconv.Atoi(v); err != nil || q.Matchday < 1 {
+			return q, scope, "matchday must be >= 1", nil
+		}
+	}
+
+	q.Sort = models.SortDate
+	if v := strings.TrimSpace(p.Get("sort")); v != "" {
+		if q.Sort = models.MatchSort(v); !q.Sort.Valid() {
+			return q, scope, "sort must be dateTime|-dateTime|matchday|-matchday", nil
+		}
+	}
+	if v := strings.TrimSpace(p.Get("cursor")); v != "" {
+		c, err := models.DecodeCursor(v)
+		if err != nil {
+			return q, scope, "invalid cursor", nil
+		}
+		if c.Sort != q.Sort {
+			return q, scope, "cursor belongs to another sort", nil
+		}
+		q.After = &c
+	}
+
+	q.Limit = defaultMatchLimit
+	if v := strings.TrimSpace(p.Get("limit")); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 1 || q.Limit > maxMatchLimit {
+			return q, scope, "limit must be 1.." + strconv.Itoa(maxMatchLimit), nil
+		}
+	}
+	for _, inc := range strings.Split(p.Get("include"), ",") {
+		switch strings.TrimSpace(inc) {
+		case "":
+		case "events":
+			q.Events = true
+		default:
+			return q, scope, "include must be events", nil
+		}
+	}
+	return q, scope, "", nil
+}
+
+// leagueQuery limits q to ?league= and ?season= (see resolveSeason).
+// msg explains a bad parameter.
+func leagueQuery(ctx context.Context, seasons repository.SeasonStore, teams repository.TeamStore, p url.Values, q *models.MatchQuery) (scope seasonScope, msg string, err error) {
+	league := strings.TrimSpace(p.Get("league"))
+
+	scope, err = resolveSeason(ctx, seasons, teams, league, strings.TrimSpace(p.Get("season")))
+	if err != nil {
+		return scope, "", err
+	}
+	q.Seasons = scope.IDs
+	if league == "" {
+		return scope, "", nil
+	}
+	inLeague, err := teams.ListByLeague(ctx, league)
+	if err != nil {
+		return scope, "", err
+	}
+	if len(inLeague) == 0 {
+		return scope, "unknown league", nil
+	}
+	if q.Seasons == nil {
+		// every season of the league
+		list, err := seasons.List(ctx, league)
+		if err != nil {
+			return scope, "", err
+		}
+		q.Seasons = []string{""}
+		for _, s := range list {
+			q.Seasons = append(q.Seasons, s.ID)
+		}
+	}
+	// matches without a season belong to their home team's league
+	q.NoSeasonHome = []string{}
+	for _, t := range inLeague {
+		q.NoSeasonHome = append(q.NoSeasonHome, t.Code)
+	}
+	return scope, "", nil
+}
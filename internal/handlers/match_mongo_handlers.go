@@ -3,34 +3,34 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"final-by-me/internal/live"
 	"final-by-me/internal/models"
+	"final-by-me/internal/projection"
 	"final-by-me/internal/repository"
+	"final-by-me/internal/squad"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MatchMongoHandler struct {
-	matches *repository.MatchRepo
-	teams   *repository.TeamRepo
-	events  chan<- models.EventLog
-}
-
-func NewMatchMongoHandler(matches *repository.MatchRepo, teams *repository.TeamRepo, events chan<- models.EventLog) *MatchMongoHandler {
-	return &MatchMongoHandler{matches: matches, teams: teams, events: events}
+	matches   repository.MatchStore
+	teams     repository.TeamStore
+	seasons   repository.SeasonStore
+	players   repository.PlayerStore
+	rules     repository.RulesStore
+	projector *projection.Projector
+	events    chan<- models.EventLog
+	hub       *live.Hub
 }
 
-func (h *MatchMongoHandler) ListMatches(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
-	defer cancel()
-
-	list, err := h.matches.List(ctx)
-	if err != nil {
-		writeJSON(w, 500, map[string]string{"error": "db error"})
-		return
-	}
-	writeJSON(w, 200, map[string]any{"matches": list, "count": len(list)})
+func NewMatchMongoHandler(matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore, players repository.PlayerStore, rules repository.RulesStore, projector *projection.Projector, events chan<- models.EventLog, hub *live.Hub) *MatchMongoHandler {
+	return &MatchMongoHandler{matches: matches, teams: teams, seasons: seasons, players: players, rules: rules, projector: projector, events: events, hub: hub}
 }
 
 // Create match: matchKey auto-generated
@@ -39,6 +39,9 @@ func (h *MatchMongoHandler) CreateMatch(w http.ResponseWriter, r *http.Request)
 		DateTime string `json:"dateTime"` // RFC3339
 		HomeCode string `json:"homeCode"`
 		AwayCode string `json:"awayCode"`
+		Knockout bool   `json:"knockout"` // cup tie: a level score goes to a shootout
+		Season   string `json:"season"`   // optional: defaults to the home league's season covering dateTime
+		Matchday int    `json:"matchday"` // optional league round
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
@@ -57,6 +60,10 @@ func (h *MatchMongoHandler) CreateMatch(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, 400, map[string]string{"error": "homeCode and awayCode must differ"})
 		return
 	}
+	if req.Matchday < 0 {
+		writeJSON(w, 400, map[string]string{"error": "matchday must be >= 1 (0 or omitted for none)"})
+		return
+	}
 
 	dt, err := time.Parse(time.RFC3339, req.DateTime)
 	if err != nil {
@@ -78,17 +85,30 @@ func (h *MatchMongoHandler) CreateMatch(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	season, msg, err := h.seasonFor(ctx, strings.TrimSpace(req.Season), req.HomeCode, req.AwayCode, dt)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+
 	//  unique matchKey
 	matchKey := req.HomeCode + "-" + req.AwayCode + "-" + time.Now().Format("20060102-150405")
 
 	m := models.Match{
 		MatchKey:  matchKey,
+		Season:    season,
+		Matchday:  req.Matchday,
 		DateTime:  dt,
 		HomeCode:  req.HomeCode,
 		AwayCode:  req.AwayCode,
 		HomeGoals: 0,
 		AwayGoals: 0,
 		Status:    models.Scheduled,
+		Knockout:  req.Knockout,
 		Events:    []models.MatchEvent{},
 	}
 
@@ -102,6 +122,9 @@ func (h *MatchMongoHandler) CreateMatch(w http.ResponseWriter, r *http.Request)
 }
 
 // PATCH /matches/{key}/events
+// Players are given by id ("playerId", "playerOutId", "playerInId") and must be
+// registered to teamCode; free-text names only for teams without a squad.
+// Players suspended for the match are rejected with 409.
 func (h *MatchMongoHandler) AddEvent(w http.ResponseWriter, r *http.Request) {
 	key := strings.TrimSpace(r.PathValue("key"))
 	if key == "" {
@@ -115,45 +138,11 @@ func (h *MatchMongoHandler) AddEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
-	req.TeamCode = strings.ToUpper(strings.TrimSpace(req.TeamCode))
-	req.Player = strings.TrimSpace(req.Player)
-	req.Detail = strings.TrimSpace(req.Detail)
-	req.CardColor = strings.ToLower(strings.TrimSpace(req.CardColor))
-	req.PlayerOut = strings.TrimSpace(req.PlayerOut)
-	req.PlayerIn = strings.TrimSpace(req.PlayerIn)
-
-	if req.Type == "" || req.TeamCode == "" || req.Minute <= 0 || req.Minute > 130 {
-		writeJSON(w, 400, map[string]string{"error": "type, teamCode, minute required (minute 1..130)"})
+	if msg := req.Normalize(); msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
 		return
 	}
 
-	allowed := map[string]bool{"goal": true, "card": true, "injury": true, "var": true, "sub": true}
-	if !allowed[req.Type] {
-		writeJSON(w, 400, map[string]string{"error": "type must be goal|card|injury|var|sub"})
-		return
-	}
-
-	// validation by type
-	if req.Type == "goal" {
-		if req.Player == "" {
-			writeJSON(w, 400, map[string]string{"error": "goal requires player"})
-			return
-		}
-	}
-	if req.Type == "card" {
-		if req.Player == "" || (req.CardColor != "yellow" && req.CardColor != "red") {
-			writeJSON(w, 400, map[string]string{"error": "card requires player and cardColor yellow|red"})
-			return
-		}
-	}
-	if req.Type == "sub" {
-		if req.PlayerOut == "" || req.PlayerIn == "" {
-			writeJSON(w, 400, map[string]string{"error": "sub requires playerOut and playerIn"})
-			return
-		}
-	}
-
 	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
 	defer cancel()
 
@@ -174,8 +163,11 @@ func (h *MatchMongoHandler) AddEvent(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 404, map[string]string{"error": "match not found"})
 		return
 	}
-	if m.Status == models.Finished {
-		writeJSON(w, 409, map[string]string{"error": "match already finished"})
+	if m.Status != models.Scheduled && m.Status != models.Live {
+		writeJSON(w, 409, map[string]string{"error": "events can only be added to scheduled or live matches", "status": string(m.Status)})
+		return
+	}
+	if !h.seasonOpen(ctx, w, m) {
 		return
 	}
 	// Only allow events for teams playing in this match
@@ -183,17 +175,37 @@ func (h *MatchMongoHandler) AddEvent(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 400, map[string]string{"error": "teamCode is not playing in this match"})
 		return
 	}
+	if msg, err := squad.ResolveEvent(ctx, h.players, &req, nil, false); err != nil || msg != "" {
+		writeResolveError(w, msg, err)
+		return
+	}
+	if msg, err := checkSuspensions(ctx, h.matches, h.teams, h.seasons, h.rules, m, req); err != nil || msg != "" {
+		writeSuspensionError(w, msg, err)
+		return
+	}
+
+	if err := models.CheckEventTime(m.Period, req.Minute, req.Stoppage); err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+
+	req.Period = m.Period
+	req.ID = primitive.NewObjectID().Hex()
+	req.CreatedAt = time.Now().UTC()
+	req.CreatedBy = actorID(r)
 
 	//  repo allows status scheduled OR live (your $in filter)
 	if err := h.matches.AddEvent(ctx, key, m, req); err != nil {
 		writeJSON(w, 500, map[string]string{"error": "update error"})
 		return
 	}
+	h.publish(ctx, key, live.Update{Type: live.TypeEvent, Event: &req})
 
-	writeJSON(w, 200, map[string]string{"status": "ok"})
+	writeJSON(w, 200, map[string]any{"status": "ok", "event": req})
 }
 
 // PATCH /matches/{key}/status
+// Body: {"status":"live","reason":"optional"}. Illegal transitions return 409 with the allowed next states.
 func (h *MatchMongoHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
 	key := strings.TrimSpace(r.PathValue("key"))
 	if key == "" {
@@ -202,7 +214,8 @@ func (h *MatchMongoHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Status string `json:"status"` // scheduled | live | finished
+		Status string `json:"status"` // scheduled | live | finished | postponed | suspended | abandoned | cancelled (awarded: see /award)
+		Reason string `json:"reason"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
@@ -210,8 +223,8 @@ func (h *MatchMongoHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s := models.MatchStatus(strings.ToLower(strings.TrimSpace(req.Status)))
-	if s != models.Scheduled && s != models.Live && s != models.Finished {
-		writeJSON(w, 400, map[string]string{"error": "status must be scheduled|live|finished"})
+	if !s.Valid() {
+		writeJSON(w, 400, map[string]string{"error": "status must be scheduled|live|finished|postponed|suspended|abandoned|cancelled"})
 		return
 	}
 
@@ -228,28 +241,7 @@ func (h *MatchMongoHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// rule: finished cannot go back
-	if m.Status == models.Finished && s != models.Finished {
-		writeJSON(w, 409, map[string]string{"error": "finished match cannot be changed"})
-		return
-	}
-
-	// if status finished -> use Finalize too (keeps rules consistent)
-	if s == models.Finished {
-		if err := h.matches.Finalize(ctx, key); err != nil {
-			writeJSON(w, 500, map[string]string{"error": "finalize error"})
-			return
-		}
-		writeJSON(w, 200, map[string]string{"status": "finished"})
-		return
-	}
-
-	if err := h.matches.SetStatus(ctx, key, s); err != nil {
-		writeJSON(w, 500, map[string]string{"error": "update error"})
-		return
-	}
-
-	writeJSON(w, 200, map[string]string{"status": string(s)})
+	h.transition(ctx, w, r, m, s, strings.TrimSpace(req.Reason))
 }
 
 // POST /matches/{key}/finalize
@@ -263,7 +255,7 @@ func (h *MatchMongoHandler) Finalize(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
 	defer cancel()
 
-	_, found, err := h.matches.FindByKey(ctx, key)
+	m, found, err := h.matches.FindByKey(ctx, key)
 	if err != nil {
 		writeJSON(w, 500, map[string]string{"error": "db error"})
 		return
@@ -273,10 +265,108 @@ func (h *MatchMongoHandler) Finalize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.matches.Finalize(ctx, key); err != nil {
-		writeJSON(w, 500, map[string]string{"error": "finalize error"})
-		return
+	h.transition(ctx, w, r, m, models.Finished, "")
+}
+
+// seasonFor picks the season of a new match: the requested one, else the season
+// of the home team's league whose dates cover dt. Leagues without seasons give "".
+// msg explains why the match cannot be placed.
+func (h *MatchMongoHandler) seasonFor(ctx context.Context, id, home, away string, dt time.Time) (season string, msg string, err error) {
+	var s models.Season
+	if id != "" {
+		found := false
+		if s, found, err = h.seasons.Find(ctx, id); err != nil {
+			return "", "", err
+		}
+		if !found {
+			return "", "season not found", nil
+		}
+	} else {
+		t, found, err := h.teams.Find(ctx, home)
+		if err != nil || !found {
+			return "", "", err
+		}
+		list, err := h.seasons.List(ctx, t.League)
+		if err != nil {
+			return "", "", err
+		}
+		if len(list) == 0 {
+			return "", "", nil
+		}
+		covered := false
+		for _, c := range list {
+			if c.Contains(dt) {
+				s, covered = c, true
+				break
+			}
+		}
+		if !covered {
+			return "", "no " + t.League + " season covers dateTime; pass season", nil
+		}
+	}
+
+	if s.Status != models.SeasonOpen {
+		return "", "season " + s.ID + " is closed", nil
+	}
+	if !s.HasTeam(home) || !s.HasTeam(away) {
+		return "", "both teams must take part in season " + s.ID, nil
 	}
+	return s.ID, "", nil
+}
 
-	writeJSON(w, 200, map[string]string{"status": "finished"})
+// transition applies one lifecycle change to m and writes the response.
+// Every status write goes through here so the rules stay in one place.
+func (h *MatchMongoHandler) transition(ctx context.Context, w http.ResponseWriter, r *http.Request, m models.Match, to models.MatchStatus, reason string) bool {
+	// moves that carry more than a status have their own endpoints
+	switch {
+	case to == models.Awarded:
+		writeJSON(w, 409, map[string]string{"error": "use POST /matches/{key}/award to award a result"})
+		return false
+	case m.Status == models.Abandoned && to == models.Scheduled:
+		writeJSON(w, 409, map[string]string{"error": "use POST /matches/{key}/replay to replay an abandoned match"})
+		return false
+	case m.Status == models.Postponed && to == models.Scheduled:
+		writeJSON(w, 409, map[string]string{"error": "use POST /matches/{key}/reschedule to set a new date"})
+		return false
+	}
+
+	if to == models.Finished {
+		if msg := finalizeBlocker(m); msg != "" {
+			writeJSON(w, 409, map[string]string{"error": msg})
+			return false
+		}
+	}
+
+	change := models.StatusChange{From: m.Status, To: to, At: time.Now().UTC(), By: actorID(r), Reason: reason}
+	if err := h.matches.SetStatus(ctx, m.MatchKey, change); err != nil {
+		writeStatusError(w, err)
+		return false
+	}
+
+	typ := live.TypeStatus
+	if to == models.Finished {
+		typ = live.TypeFinished
+	}
+	h.publish(ctx, m.MatchKey, live.Update{Type: typ, Status: to})
+	h.logEvent("status_"+string(to), m.MatchKey, fmt.Sprintf("%s -> %s by %s", m.Status, to, change.By))
+
+	writeJSON(w, 200, map[string]any{"status": to, "from": m.Status, "allowed": to.NextStatuses()})
+	return true
+}
+
+func writeStatusError(w http.ResponseWriter, err error) {
+	var te *repository.TransitionError
+	switch {
+	case errors.As(err, &te):
+		writeJSON(w, 409, map[string]any{
+			"error":   "illegal status transition",
+			"from":    te.From,
+			"to":      te.To,
+			"allowed": te.Allowed,
+		})
+	case errors.Is(err, repository.ErrConflict):
+		writeJSON(w, 409, map[string]string{"error": "status was changed concurrently, reload and retry"})
+	default:
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+	}
 }
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/live"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// POST /matches/{key}/shootout/kicks
+// Body: {"teamCode":"ARS","taker":"Saka","outcome":"scored|missed|saved"}
+// Teams alternate; the team of the first kick starts. Kicks stop once the shootout is decided.
+func (h *MatchMongoHandler) AddShootoutKick(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	if key == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key"})
+		return
+	}
+
+	var req struct {
+		TeamCode string `json:"teamCode"`
+		Taker    string `json:"taker"`
+		Outcome  string `json:"outcome"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	req.TeamCode = strings.ToUpper(strings.TrimSpace(req.TeamCode))
+	req.Taker = strings.TrimSpace(req.Taker)
+	req.Outcome = strings.ToLower(strings.TrimSpace(req.Outcome))
+
+	if req.TeamCode == "" || req.Taker == "" {
+		writeJSON(w, 400, map[string]string{"error": "teamCode and taker required"})
+		return
+	}
+	if req.Outcome != models.KickScored && req.Outcome != models.KickMissed && req.Outcome != models.KickSaved {
+		writeJSON(w, 400, map[string]string{"error": "outcome must be scored|missed|saved"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+	if req.TeamCode != m.HomeCode && req.TeamCode != m.AwayCode {
+		writeJSON(w, 400, map[string]string{"error": "teamCode is not playing in this match"})
+		return
+	}
+	if !m.Knockout {
+		writeJSON(w, 409, map[string]string{"error": "shootouts are only played in knockout matches"})
+		return
+	}
+	if m.Status != models.Live || m.Period != models.PeriodPenalties {
+		writeJSON(w, 409, map[string]string{"error": "match must be live and in the penalties period"})
+		return
+	}
+	if !m.Level() {
+		writeJSON(w, 409, map[string]string{"error": "match is not level"})
+		return
+	}
+
+	var so models.Shootout
+	if m.Shootout != nil {
+		so = *m.Shootout
+	}
+	if so.Completed {
+		writeJSON(w, 409, map[string]string{"error": "shootout already decided", "winner": so.Winner})
+		return
+	}
+	if next := so.NextKicker(m.HomeCode, m.AwayCode); next != "" && next != req.TeamCode {
+		writeJSON(w, 409, map[string]string{"error": "it is " + next + "'s kick"})
+		return
+	}
+
+	kick := models.ShootoutKick{
+		ID:        primitive.NewObjectID().Hex(),
+		Order:     len(so.Kicks) + 1,
+		TeamCode:  req.TeamCode,
+		Taker:     req.Taker,
+		Outcome:   req.Outcome,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: actorID(r),
+	}
+	next := so.WithKick(m.HomeCode, m.AwayCode, kick)
+
+	if err := h.matches.SetShootout(ctx, key, next); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]string{"error": "shootout was changed concurrently, reload and retry"})
+			return
+		}
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+		return
+	}
+	h.publish(ctx, key, live.Update{Type: live.TypeShootout, Status: m.Status})
+
+	writeJSON(w, 200, map[string]any{"status": "ok", "kick": kick, "shootout": next})
+}
+
+// finalizeBlocker explains why m cannot be finished yet ("" if it can).
+func finalizeBlocker(m models.Match) string {
+	if m.Knockout && m.Level() && (m.Shootout == nil || !m.Shootout.Completed) {
+		return "knockout match is level: complete the penalty shootout first"
+	}
+	return ""
+}
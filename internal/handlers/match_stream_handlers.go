@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"final-by-me/internal/live"
+)
+
+// publish reloads the match after a successful write, folds its result into
+// the standings projection and pushes it to live subscribers.
+func (h *MatchMongoHandler) publish(ctx context.Context, key string, u live.Update) {
+	m, found, err := h.matches.FindByKey(ctx, key)
+	if err != nil || !found {
+		return
+	}
+	if h.projector != nil {
+		if err := h.projector.Apply(ctx, m); err != nil {
+			// the table stays behind until the next rebuild (rebuild-standings)
+			h.logEvent("standings_error", key, err.Error())
+		}
+	}
+	if h.hub == nil {
+		return
+	}
+	u.MatchKey = key
+	u.Match = &m
+	if t, found, err := h.teams.Find(ctx, m.HomeCode); err == nil && found {
+		u.League = t.League // looked up per update, so league changes are followed
+	}
+	h.hub.Publish(u)
+}
+
+// GET /matches/{key}/stream
+// Server-Sent Events: snapshot on connect, then event/status/finished updates.
+// The stream ends once the match reaches a terminal status.
+// Supports Last-Event-ID (header or ?lastEventId=) to resume after reconnect.
+func (h *MatchMongoHandler) StreamMatch(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.PathValue("key"))
+	if key == "" {
+		writeJSON(w, 400, map[string]string{"error": "missing match key"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok || h.hub == nil {
+		writeJSON(w, 500, map[string]string{"error": "streaming not supported"})
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+
+	// subscribe before reading the match so nothing published in between is lost
+	sub := h.hub.Subscribe(64, func(u live.Update) bool { return u.MatchKey == key })
+	defer sub.Close()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	m, found, err := h.matches.FindByKey(ctx, key)
+	cancel()
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "match not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	var sent int64
+	resumed := false
+	if id, err := strconv.ParseInt(lastID, 10, 64); err == nil && id > 0 {
+		if missed, ok := h.hub.Since(key, id); ok {
+			resumed = true
+			sent = id
+			for _, u := range missed {
+				if writeSSE(w, u) != nil {
+					return
+				}
+				sent = u.ID
+			}
+		}
+	}
+	if !resumed {
+		// snapshot carries no id so it does not move the client's Last-Event-ID
+		if writeSSE(w, live.Update{Type: live.TypeSnapshot, MatchKey: key, Match: &m, Status: m.Status, At: time.Now()}) != nil {
+			return
+		}
+	}
+
+	if m.Status.Terminal() {
+		// tell the client to stop instead of letting EventSource reconnect
+		writeSSE(w, live.Update{Type: live.TypeFinished, MatchKey: key, Match: &m, Status: m.Status, At: time.Now()})
+		flusher.Flush()
+		return
+	}
+	flusher.Flush()
+
+	ping := time.NewTicker(25 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case u, ok := <-sub.C:
+			if !ok {
+				// evicted as slow consumer; the client reconnects with Last-Event-ID
+				return
+			}
+			if u.ID <= sent {
+				continue
+			}
+			if writeSSE(w, u) != nil {
+				return
+			}
+			sent = u.ID
+			flusher.Flush()
+
+			if u.Type == live.TypeFinished {
+				return
+			}
+			if u.Match != nil && u.Match.Status.Terminal() {
+				// e.g. cancelled or awarded: no finished update follows
+				writeSSE(w, live.Update{Type: live.TypeFinished, MatchKey: key, Match: u.Match, Status: u.Match.Status, At: time.Now()})
+				flusher.Flush()
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, u live.Update) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	if u.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", u.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", u.Type, data)
+	return err
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PlayerHandler struct {
+	players repository.PlayerStore
+	teams   repository.TeamStore
+	events  chan<- models.EventLog
+}
+
+func NewPlayerHandler(players repository.PlayerStore, teams repository.TeamStore, events chan<- models.EventLog) *PlayerHandler {
+	return &PlayerHandler{players: players, teams: teams, events: events}
+}
+
+// GET /teams/{code}/players
+// The registered squad of a team, by shirt number.
+func (h *PlayerHandler) ListSquad(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	code, ok := h.team(ctx, w, r)
+	if !ok {
+		return
+	}
+	list, err := h.players.ListByTeam(ctx, code)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if list == nil {
+		list = []models.Player{}
+	}
+	writeJSON(w, 200, map[string]any{"team": code, "players": list, "count": len(list)})
+}
+
+// GET /players/{id}
+func (h *PlayerHandler) GetPlayer(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	p, found, err := h.players.Find(ctx, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "player not found"})
+		return
+	}
+	writeJSON(w, 200, p)
+}
+
+// POST /teams/{code}/players
+// Body: {"name":"Bukayo Saka","number":7,"position":"FW"}
+// Registers a new player to the team. 409 if the shirt number is taken.
+func (h *PlayerHandler) AddPlayer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name"`
+		Number   int    `json:"number"`
+		Position string `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	p := models.Player{
+		Name:     strings.TrimSpace(req.Name),
+		Number:   req.Number,
+		Position: strings.ToUpper(strings.TrimSpace(req.Position)),
+	}
+	if msg := checkPlayer(p); msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	code, ok := h.team(ctx, w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	p.ID = primitive.NewObjectID().Hex()
+	p.TeamCode = code
+	p.CreatedAt, p.UpdatedAt = now, now
+
+	if err := h.players.Create(ctx, p); err != nil {
+		writePlayerWriteError(w, err)
+		return
+	}
+	sendLog(h.events, "player_registered", "", fmt.Sprintf("%s (%s) to %s by %s", p.Name, p.ID, code, actorID(r)))
+	writeJSON(w, 201, p)
+}
+
+// PATCH /teams/{code}/players/{id}
+// Body: any of {"name","number","position","teamCode"}. A new teamCode
+// transfers the player; number 0 clears the shirt number.
+func (h *PlayerHandler) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     *string `json:"name"`
+		Number   *int    `json:"number"`
+		Position *string `json:"position"`
+		TeamCode *string `json:"teamCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	p, ok := h.member(ctx, w, r)
+	if !ok {
+		return
+	}
+	from := p.TeamCode
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Number != nil {
+		p.Number = *req.Number
+	}
+	if req.Position != nil {
+		p.Position = strings.ToUpper(strings.TrimSpace(*req.Position))
+	}
+	if req.TeamCode != nil {
+		to := strings.ToUpper(strings.TrimSpace(*req.TeamCode))
+		if to != from {
+			_, found, err := h.teams.Find(ctx, to)
+			if err != nil {
+				writeJSON(w, 500, map[string]string{"error": "db error"})
+				return
+			}
+			if !found {
+				writeJSON(w, 400, map[string]string{"error": "unknown teamCode"})
+				return
+			}
+			p.TeamCode = to
+		}
+	}
+	if msg := checkPlayer(p); msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+	p.UpdatedAt = time.Now().UTC()
+
+	if err := h.players.Update(ctx, p); err != nil {
+		writePlayerWriteError(w, err)
+		return
+	}
+	if p.TeamCode != from {
+		sendLog(h.events, "player_transferred", "", fmt.Sprintf("%s (%s) %s -> %s by %s", p.Name, p.ID, from, p.TeamCode, actorID(r)))
+	}
+	writeJSON(w, 200, p)
+}
+
+// DELETE /teams/{code}/players/{id}
+// Releases the player from the squad. The player is kept, since past match
+// events reference it.
+func (h *PlayerHandler) ReleasePlayer(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	p, ok := h.member(ctx, w, r)
+	if !ok {
+		return
+	}
+	from := p.TeamCode
+	p.TeamCode = ""
+	p.Number = 0
+	p.UpdatedAt = time.Now().UTC()
+
+	if err := h.players.Update(ctx, p); err != nil {
+		writePlayerWriteError(w, err)
+		return
+	}
+	sendLog(h.events, "player_released", "", fmt.Sprintf("%s (%s) from %s by %s", p.Name, p.ID, from, actorID(r)))
+	writeJSON(w, 200, p)
+}
+
+// team resolves the {code} of the path; it writes the error response when
+// the team does not exist.
+func (h *PlayerHandler) team(ctx context.Context, w http.ResponseWriter, r *http.Request) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(r.PathValue("code")))
+	_, found, err := h.teams.Find(ctx, code)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return "", false
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "team not found"})
+		return "", false
+	}
+	return code, true
+}
+
+// member loads the player {id} of the path, which must be in the squad {code}.
+func (h *PlayerHandler) member(ctx context.Context, w http.ResponseWriter, r *http.Request) (models.Player, bool) {
+	code := strings.ToUpper(strings.TrimSpace(r.PathValue("code")))
+	p, found, err := h.players.Find(ctx, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return models.Player{}, false
+	}
+	if !found || p.TeamCode != code {
+		writeJSON(w, 404, map[string]string{"error": "player not found in " + code})
+		return models.Player{}, false
+	}
+	return p, true
+}
+
+// checkPlayer validates the editable fields of p.
+func checkPlayer(p models.Player) string {
+	if p.Name == "" {
+		return "name required"
+	}
+	if p.Number < 0 || p.Number > 99 {
+		return "number must be 1..99 (0 for none)"
+	}
+	if p.Position != "" && !models.ValidPosition(p.Position) {
+		return "position must be GK|DF|MF|FW"
+	}
+	return ""
+}
+
+func writePlayerWriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrConflict):
+		writeJSON(w, 409, map[string]string{"error": "shirt number already taken in this squad"})
+	case errors.Is(err, repository.ErrNotFound):
+		writeJSON(w, 404, map[string]string{"error": "player not found"})
+	default:
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+	}
+}
@@ -0,0 +1,454 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expect sends a request with token ("" for none) and checks the status and
+// that the JSON object answered has keys. It returns the object.
+func expect(t *testing.T, srv *httptest.Server, token, method, path, body string, status int, keys ...string) map[string]any {
+	t.Helper()
+	var got map[string]any
+	if code := callAs(t, srv, token, method, path, body, &got); code != status {
+		t.Fatalf("%s %s: status %d, want %d (%v)", method, path, code, status, got)
+	}
+	for _, k := range keys {
+		if _, ok := got[k]; !ok {
+			t.Fatalf("%s %s: no %q in %v", method, path, k, got)
+		}
+	}
+	return got
+}
+
+// get fetches a non-JSON public route.
+func get(t *testing.T, srv *httptest.Server, path string) (*http.Response, string) {
+	t.Helper()
+	res, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(b)
+}
+
+func TestAdminRoutesNeedAdmin(t *testing.T) {
+	srv := newServer(t)
+	path := strings.NewReplacer("{league}", "EPL", "{code}", "ARS", "{id}", "x", "{key}", "x")
+	for _, rt := range adminRoutes {
+		method, pattern, _ := strings.Cut(rt, " ")
+		p := path.Replace(pattern)
+		expect(t, srv, "", method, p, "{}", 401, "error")
+		expect(t, srv, "not-a-token", method, p, "{}", 401, "error")
+		expect(t, srv, userToken, method, p, "{}", 403, "error")
+	}
+}
+
+func TestAuthRoutes(t *testing.T) {
+	srv := newServer(t)
+	body := `{"email":"Fan@Example.com","name":"Fan","password":"secret","favoriteLeague":"EPL","favoriteTeamCode":"ars"}`
+	u := expect(t, srv, "", "POST", "/auth/register", body, 201, "id", "email", "role", "favoriteTeamCode")
+	if u["email"] != "fan@example.com" || u["role"] != "user" || u["favoriteTeamCode"] != "ARS" {
+		t.Fatalf("registered user = %v", u)
+	}
+	expect(t, srv, "", "POST", "/auth/register", body, 409, "error")
+	expect(t, srv, "", "POST", "/auth/register", `{"email":"x@example.com","password":"p","favoriteLeague":"KPL","favoriteTeamCode":"ARS"}`, 400, "error")
+	expect(t, srv, "", "POST", "/auth/register", `{"email":"x@example.com"}`, 400, "error")
+
+	login := expect(t, srv, "", "POST", "/auth/login", `{"email":"fan@example.com","password":"secret"}`, 200, "token", "role")
+	expect(t, srv, "", "POST", "/auth/login", `{"email":"fan@example.com","password":"wrong"}`, 401, "error")
+	expect(t, srv, "", "POST", "/auth/login", `{"email":"nobody@example.com","password":"secret"}`, 401, "error")
+
+	// a signed-in fan is still no admin
+	token, _ := login["token"].(string)
+	expect(t, srv, token, "POST", "/matches", `{}`, 403, "error")
+}
+
+func TestTeamAndLeagueRoutes(t *testing.T) {
+	srv := newServer(t)
+
+	res, page := get(t, srv, "/")
+	if res.StatusCode != 200 || !strings.Contains(page, "<html") {
+		t.Fatalf("GET /: status %d", res.StatusCode)
+	}
+
+	leagues := expect(t, srv, "", "GET", "/leagues", "", 200, "leagues")
+	if l, _ := leagues["leagues"].([]any); len(l) == 0 {
+		t.Fatalf("leagues = %v", leagues)
+	}
+	teams := expect(t, srv, "", "GET", "/teams?league=KPL", "", 200, "teams")
+	if l, _ := teams["teams"].([]any); len(l) != 12 {
+		t.Fatalf("KPL teams = %d, want 12", len(l))
+	}
+	expect(t, srv, "", "GET", "/leagues/EPL/rules", "", 200, "league", "pointsWin", "tieBreakers", "discipline")
+
+	rules := `{"pointsWin":2,"pointsDraw":1,"pointsLoss":0,"tieBreakers":["wins"]}`
+	expect(t, srv, adminToken, "PUT", "/leagues/EPL/rules", rules, 200, "league", "pointsWin")
+	got := expect(t, srv, "", "GET", "/leagues/EPL/rules", "", 200, "pointsWin")
+	if got["pointsWin"] != 2.0 {
+		t.Fatalf("pointsWin after PUT = %v, want 2", got["pointsWin"])
+	}
+	expect(t, srv, adminToken, "PUT", "/leagues/EPL/rules", `{"pointsWin":3,"tieBreakers":["coin_toss"]}`, 400, "error")
+	expect(t, srv, adminToken, "PUT", "/leagues/XXX/rules", rules, 404, "error")
+
+	team := expect(t, srv, adminToken, "PATCH", "/teams/ARS", `{"name":"Arsenal FC"}`, 200, "code", "name", "league")
+	if team["name"] != "Arsenal FC" {
+		t.Fatalf("renamed team = %v", team)
+	}
+	expect(t, srv, adminToken, "PATCH", "/teams/XXX", `{"name":"Nobody"}`, 404, "error")
+
+	expect(t, srv, "", "GET", "/teams/ARS/positions", "", 200, "teamCode", "league", "positions", "count")
+	expect(t, srv, "", "GET", "/teams/XXX/positions", "", 404, "error")
+}
+
+func TestPlayerRoutes(t *testing.T) {
+	srv := newServer(t)
+
+	p := expect(t, srv, adminToken, "POST", "/teams/ARS/players", `{"name":"Bukayo Saka","number":7,"position":"FW"}`, 201, "id", "name", "teamCode", "number")
+	id, _ := p["id"].(string)
+	expect(t, srv, adminToken, "POST", "/teams/ARS/players", `{"name":"Someone Else","number":7}`, 409, "error")
+	expect(t, srv, adminToken, "POST", "/teams/ARS/players", `{"number":8}`, 400, "error")
+
+	squad := expect(t, srv, "", "GET", "/teams/ARS/players", "", 200, "team", "players", "count")
+	if squad["count"] != 1.0 {
+		t.Fatalf("squad = %v", squad)
+	}
+	expect(t, srv, "", "GET", "/players/"+id, "", 200, "id", "name", "teamCode")
+	expect(t, srv, "", "GET", "/players/nobody", "", 404, "error")
+
+	moved := expect(t, srv, adminToken, "PATCH", "/teams/ARS/players/"+id, `{"number":11}`, 200, "id", "number")
+	if moved["number"] != 11.0 {
+		t.Fatalf("updated player = %v", moved)
+	}
+	released := expect(t, srv, adminToken, "DELETE", "/teams/ARS/players/"+id, "", 200, "id")
+	if _, ok := released["teamCode"]; ok {
+		t.Fatalf("released player still has a team: %v", released)
+	}
+	expect(t, srv, adminToken, "DELETE", "/teams/ARS/players/"+id, "", 404, "error")
+}
+
+func TestSeasonRoutes(t *testing.T) {
+	srv := newServer(t)
+
+	body := `{"league":"KPL","name":"2030","startDate":"2030-03-01","endDate":"2030-11-30"}`
+	created := expect(t, srv, adminToken, "POST", "/seasons", body, 201, "season", "assignedMatches")
+	season, _ := created["season"].(map[string]any)
+	id, _ := season["id"].(string)
+	expect(t, srv, adminToken, "POST", "/seasons", body, 409, "error")
+	expect(t, srv, adminToken, "POST", "/seasons", `{"league":"KPL"}`, 400, "error")
+
+	list := expect(t, srv, "", "GET", "/seasons?league=KPL", "", 200, "seasons", "current", "count")
+	if list["count"] != 1.0 {
+		t.Fatalf("seasons = %v", list)
+	}
+	got := expect(t, srv, "", "GET", "/seasons/"+id, "", 200, "id", "league", "status", "teams")
+	if got["status"] != "open" {
+		t.Fatalf("season = %v", got)
+	}
+	expect(t, srv, "", "GET", "/seasons/nope", "", 404, "error")
+
+	closed := expect(t, srv, adminToken, "POST", "/seasons/"+id+"/close", "", 200, "season", "status", "table", "unfinished")
+	if closed["status"] != "closed" {
+		t.Fatalf("closed season = %v", closed)
+	}
+	expect(t, srv, adminToken, "POST", "/seasons/"+id+"/close", "", 409, "error")
+}
+
+func TestFixtureRoutes(t *testing.T) {
+	srv := newServer(t)
+
+	preview := expect(t, srv, adminToken, "POST", "/leagues/KPL/fixtures?preview=true", `{"start":"2030-03-07T15:00:00Z","spacingDays":7}`, 200, "preview", "schedule")
+	if preview["preview"] != true {
+		t.Fatalf("preview = %v", preview)
+	}
+	expect(t, srv, adminToken, "POST", "/leagues/KPL/fixtures", `{"start":"soon"}`, 400, "error")
+	expect(t, srv, adminToken, "POST", "/leagues/KPL/fixtures", `{"start":"2030-03-07T15:00:00Z","spacingDays":7}`, 201, "inserted", "schedule")
+
+	m := createMatch(t, srv, "ARS", "CHE")
+	key := "/matches/" + m.MatchKey
+	expect(t, srv, adminToken, "PATCH", key, `{"matchday":4}`, 200, "matchKey", "matchday")
+	expect(t, srv, adminToken, "POST", key+"/postpone", `{"reason":"waterlogged pitch"}`, 200, "status", "from", "allowed")
+	expect(t, srv, adminToken, "POST", key+"/postpone", `{}`, 400, "error")
+	moved := expect(t, srv, adminToken, "POST", key+"/reschedule", `{"dateTime":"2030-08-23T14:00:00Z","reason":"new date"}`, 200, "status", "reschedules")
+	if moved["status"] != "scheduled" {
+		t.Fatalf("rescheduled match = %v", moved)
+	}
+
+	// suspended, resumed, abandoned and replayed
+	expect(t, srv, adminToken, "PATCH", key+"/status", `{"status":"live"}`, 200, "status", "from", "allowed")
+	expect(t, srv, adminToken, "PATCH", key+"/status", `{"status":"suspended"}`, 200, "status")
+	expect(t, srv, adminToken, "POST", key+"/replay", `{"dateTime":"2030-09-01T14:00:00Z","reason":"fog"}`, 409, "error")
+	expect(t, srv, adminToken, "POST", key+"/resume", "", 200, "status")
+	expect(t, srv, adminToken, "PATCH", key+"/status", `{"status":"abandoned"}`, 200, "status")
+	replayed := expect(t, srv, adminToken, "POST", key+"/replay", `{"dateTime":"2030-09-01T14:00:00Z","reason":"fog"}`, 200, "status", "attempts")
+	if replayed["status"] != "scheduled" {
+		t.Fatalf("replayed match = %v", replayed)
+	}
+
+	awarded := expect(t, srv, adminToken, "POST", key+"/award", `{"homeGoals":3,"awayGoals":0,"reason":"ineligible player"}`, 200, "status", "award")
+	if awarded["status"] != "awarded" {
+		t.Fatalf("awarded match = %v", awarded)
+	}
+	expect(t, srv, adminToken, "POST", "/matches/nope/award", `{"homeGoals":3,"awayGoals":0,"reason":"x"}`, 404, "error")
+}
+
+func TestPeriodAndShootoutRoutes(t *testing.T) {
+	srv := newServer(t)
+	var m map[string]any
+	body := `{"homeCode":"LIV","awayCode":"MCI","dateTime":"2030-08-16T14:00:00Z","knockout":true}`
+	if code := call(t, srv, "POST", "/matches", body, &m); code != 201 {
+		t.Fatalf("create knockout match: status %d", code)
+	}
+	key := "/matches/" + m["matchKey"].(string)
+
+	expect(t, srv, adminToken, "PATCH", key+"/period", `{"period":"half_time"}`, 409, "error") // not live yet
+	expect(t, srv, adminToken, "PATCH", key+"/status", `{"status":"live"}`, 200, "status")
+	for _, p := range []string{"half_time", "second_half", "penalties"} {
+		got := expect(t, srv, adminToken, "PATCH", key+"/period", `{"period":"`+p+`"}`, 200, "status", "period")
+		if got["period"] != p {
+			t.Fatalf("period = %v, want %s", got["period"], p)
+		}
+	}
+	expect(t, srv, adminToken, "PATCH", key+"/period", `{"period":"overtime"}`, 400, "error")
+
+	kick := expect(t, srv, adminToken, "POST", key+"/shootout/kicks", `{"teamCode":"LIV","taker":"Salah","outcome":"scored"}`, 200, "status", "kick", "shootout")
+	if k, _ := kick["kick"].(map[string]any); k["outcome"] != "scored" {
+		t.Fatalf("kick = %v", kick)
+	}
+	expect(t, srv, adminToken, "POST", key+"/shootout/kicks", `{"teamCode":"LIV","taker":"Salah","outcome":"wide"}`, 400, "error")
+}
+
+func TestImportRoute(t *testing.T) {
+	srv := newServer(t)
+	rows := `[{"dateTime":"2030-10-01T14:00:00Z","homeCode":"ARS","awayCode":"CHE","homeGoals":1,"awayGoals":0},
+		{"dateTime":"2030-10-01T14:00:00Z","homeCode":"ARS","awayCode":"XXX"}]`
+
+	dry := expect(t, srv, adminToken, "POST", "/matches/import?dryRun=true", rows, 200, "dryRun", "rows", "created", "invalid", "results")
+	if dry["created"] != 1.0 || dry["invalid"] != 1.0 {
+		t.Fatalf("dry run = %v", dry)
+	}
+	done := expect(t, srv, adminToken, "POST", "/matches/import", rows, 200, "created")
+	if done["created"] != 1.0 {
+		t.Fatalf("import = %v", done)
+	}
+	again := expect(t, srv, adminToken, "POST", "/matches/import", rows, 200, "existing")
+	if again["existing"] != 1.0 {
+		t.Fatalf("second import = %v", again)
+	}
+	expect(t, srv, adminToken, "POST", "/matches/import?format=xml", rows, 400, "error")
+}
+
+// playOne records a finished ARS-CHE with a goal, an assist and a card.
+func playOne(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	m := createMatch(t, srv, "ARS", "CHE")
+	key := "/matches/" + m.MatchKey
+	call(t, srv, "PATCH", key+"/status", `{"status":"live"}`, nil)
+	for _, e := range []string{
+		`{"type":"goal","teamCode":"ARS","minute":10,"player":"Saka","assist":"Odegaard"}`,
+		`{"type":"card","teamCode":"CHE","minute":20,"player":"Caicedo","cardColor":"yellow"}`,
+	} {
+		if code := call(t, srv, "PATCH", key+"/events", e, nil); code != 200 {
+			t.Fatalf("add %s: status %d", e, code)
+		}
+	}
+	if code := call(t, srv, "POST", key+"/finalize", "", nil); code != 200 {
+		t.Fatalf("finalize: status %d", code)
+	}
+	return m.MatchKey
+}
+
+func TestMatchReadRoutes(t *testing.T) {
+	srv := newServer(t)
+	key := playOne(t, srv)
+
+	list := expect(t, srv, "", "GET", "/matches?team=ARS", "", 200, "matches", "count", "season")
+	if list["count"] != 1.0 {
+		t.Fatalf("matches = %v", list)
+	}
+	expect(t, srv, "", "GET", "/matches?status=nope", "", 400, "error")
+	expect(t, srv, "", "GET", "/matches/"+key, "", 200, "match", "home", "away", "timeline", "counts", "previousMeeting")
+	expect(t, srv, "", "GET", "/matches/nope", "", 404, "error")
+}
+
+func TestTableStatsAndDisciplineRoutes(t *testing.T) {
+	srv := newServer(t)
+	playOne(t, srv)
+
+	table := expect(t, srv, "", "GET", "/table?league=EPL", "", 200, "table")
+	rows, _ := table["table"].([]any)
+	if top, _ := rows[0].(map[string]any); len(rows) != 20 || top["teamCode"] != "ARS" {
+		t.Fatalf("EPL table = %v", table)
+	}
+	expect(t, srv, "", "GET", "/table?view=sideways", "", 400, "error")
+
+	stats := expect(t, srv, "", "GET", "/stats?season=all", "", 200, "totalMatches", "finishedMatches", "totalGoals", "goalsByType")
+	if stats["totalGoals"] != 1.0 {
+		t.Fatalf("stats = %v", stats)
+	}
+	scorers := expect(t, srv, "", "GET", "/stats/scorers?league=EPL", "", 200, "scorers", "count", "total")
+	if scorers["count"] != 1.0 {
+		t.Fatalf("scorers = %v", scorers)
+	}
+	assists := expect(t, srv, "", "GET", "/stats/assists?league=EPL", "", 200, "assists", "count", "total")
+	if a, _ := assists["assists"].([]any); len(a) != 1 || a[0].(map[string]any)["player"] != "Odegaard" {
+		t.Fatalf("assists = %v", assists)
+	}
+	expect(t, srv, "", "GET", "/stats/scorers?limit=0", "", 400, "error")
+
+	disc := expect(t, srv, "", "GET", "/discipline?league=EPL", "", 200, "players", "count")
+	if disc["count"] != 1.0 {
+		t.Fatalf("discipline = %v", disc)
+	}
+}
+
+func TestCalendarRoutes(t *testing.T) {
+	srv := newServer(t)
+	createMatch(t, srv, "ARS", "CHE")
+
+	for _, path := range []string{"/calendar/teams/ARS.ics", "/calendar/leagues/EPL.ics"} {
+		res, body := get(t, srv, path)
+		if res.StatusCode != 200 || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/calendar") {
+			t.Fatalf("%s: status %d, content type %q", path, res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(body, "BEGIN:VCALENDAR") || !strings.Contains(body, "BEGIN:VEVENT") {
+			t.Fatalf("%s: no calendar with events:\n%s", path, body)
+		}
+	}
+	for _, path := range []string{"/calendar/teams/ARS", "/calendar/teams/XXX.ics", "/calendar/leagues/XXX.ics"} {
+		if res, _ := get(t, srv, path); res.StatusCode != 404 {
+			t.Fatalf("%s: status %d, want 404", path, res.StatusCode)
+		}
+	}
+}
+
+func TestAdminMaintenanceRoutes(t *testing.T) {
+	srv := newServer(t)
+	playOne(t, srv)
+
+	rep := expect(t, srv, adminToken, "POST", "/admin/scores/reconcile", "", 200, "scanned", "mismatches", "repair")
+	if rep["scanned"] != 1.0 {
+		t.Fatalf("reconcile = %v", rep)
+	}
+	expect(t, srv, adminToken, "POST", "/admin/standings/rebuild", "", 200, "rebuilt", "count")
+	check := expect(t, srv, adminToken, "POST", "/admin/standings/check", "", 200, "checked", "mismatches", "repair")
+	if m, _ := check["mismatches"].([]any); len(m) != 0 {
+		t.Fatalf("standings check after rebuild = %v", check)
+	}
+}
+
+// readEvent reads a server-sent event stream up to the first event and
+// returns its name.
+func readEvent(t *testing.T, srv *httptest.Server, path string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("%s: status %d, content type %q", path, res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	sc := bufio.NewScanner(res.Body)
+	for sc.Scan() {
+		if name, ok := strings.CutPrefix(sc.Text(), "event: "); ok {
+			return name
+		}
+	}
+	t.Fatalf("%s: no event (%v)", path, sc.Err())
+	return ""
+}
+
+func TestStreamRoutes(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+
+	if ev := readEvent(t, srv, "/matches/"+m.MatchKey+"/stream"); ev != "snapshot" {
+		t.Fatalf("match stream starts with %q, want snapshot", ev)
+	}
+	expect(t, srv, "", "GET", "/matches/nope/stream", "", 404, "error")
+
+	if ev := readEvent(t, srv, "/table/stream?league=EPL"); ev != "table" {
+		t.Fatalf("table stream starts with %q, want table", ev)
+	}
+	expect(t, srv, "", "GET", "/table/stream?matchday=1", "", 400, "error")
+}
+
+func TestScoreboardRoute(t *testing.T) {
+	srv := newServer(t)
+	m := createMatch(t, srv, "ARS", "CHE")
+
+	expect(t, srv, "", "GET", "/ws/scoreboard", "", 400, "error") // no upgrade
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	handshake := "GET /ws/scoreboard?teams=ARS HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := conn.Write([]byte(handshake)); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols || res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: status %d, accept %q", res.StatusCode, res.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	// the first frame is the snapshot: a short unmasked text frame
+	var head [2]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[0] != 0x81 {
+		t.Fatalf("first frame header %#x, want a final text frame", head[0])
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	var snap struct {
+		Type    string `json:"type"`
+		Matches []struct {
+			MatchKey string `json:"matchKey"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(payload, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Type != "snapshot" || len(snap.Matches) != 1 || snap.Matches[0].MatchKey != m.MatchKey {
+		t.Fatalf("snapshot = %s", payload)
+	}
+}
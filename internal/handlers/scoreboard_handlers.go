@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"final-by-me/internal/live"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+const (
+	wsPingEvery = 30 * time.Second
+	wsPongWait  = 70 * time.Second
+)
+
+type ScoreboardHandler struct {
+	matches repository.MatchStore
+	teams   repository.TeamStore
+	hub     *live.Hub
+}
+
+func NewScoreboardHandler(matches repository.MatchStore, teams repository.TeamStore, hub *live.Hub) *ScoreboardHandler {
+	return &ScoreboardHandler{matches: matches, teams: teams, hub: hub}
+}
+
+// scoreDelta is what the scoreboard sends per match change (no full events array)
+type scoreDelta struct {
+	Type      string             `json:"type"`
+	ID        int64              `json:"id,omitempty"`
+	MatchKey  string             `json:"matchKey"`
+	League    string             `json:"league,omitempty"`
+	HomeCode  string             `json:"homeCode"`
+	AwayCode  string             `json:"awayCode"`
+	HomeGoals int                `json:"homeGoals"`
+	AwayGoals int                `json:"awayGoals"`
+	Status    models.MatchStatus `json:"status"`
+	Event     *models.MatchEvent `json:"event,omitempty"`
+}
+
+// scoreboardFilter: empty sets mean "everything"
+type scoreboardFilter struct {
+	Leagues []string `json:"leagues"`
+	Teams   []string `json:"teams"`
+	Matches []string `json:"matches"`
+}
+
+type clientMsg struct {
+	Op string `json:"op"` // subscribe | ping
+	scoreboardFilter
+}
+
+// GET /ws/scoreboard?leagues=EPL,KPL&teams=ARS&matches=KEY1,KEY2
+// Client messages:
+//
+//	{"op":"subscribe","leagues":["EPL"],"teams":[],"matches":[]}  replaces the subscription
+//	{"op":"ping"}                                                 answered with {"type":"pong"}
+func (h *ScoreboardHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	initial := normalizeFilter(scoreboardFilter{
+		Leagues: splitList(q.Get("leagues")),
+		Teams:   splitList(q.Get("teams")),
+		Matches: splitList(q.Get("matches")),
+	})
+
+	conn, err := live.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	sub := h.hub.Subscribe(256, buildScoreFilter(initial))
+	defer sub.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	h.sendSnapshot(r.Context(), conn, initial)
+
+	// reader: subscription changes and keepalive
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		extend := func() { _ = conn.SetReadDeadline(time.Now().Add(wsPongWait)) }
+		for {
+			raw, err := conn.ReadMessage(extend)
+			if err != nil {
+				return
+			}
+			extend()
+
+			var msg clientMsg
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				writeWS(conn, map[string]string{"type": "error", "error": "invalid JSON"})
+				continue
+			}
+			switch strings.ToLower(msg.Op) {
+			case "subscribe":
+				f := normalizeFilter(msg.scoreboardFilter)
+				sub.SetFilter(buildScoreFilter(f))
+				h.sendSnapshot(r.Context(), conn, f)
+			case "ping":
+				writeWS(conn, map[string]string{"type": "pong"})
+			default:
+				writeWS(conn, map[string]string{"type": "error", "error": "op must be subscribe|ping"})
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingEvery)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			conn.Close(live.CloseNormal, "")
+			return
+
+		case <-ping.C:
+			if err := conn.Ping(); err != nil {
+				conn.Close(live.CloseGoingAway, "")
+				return
+			}
+
+		case u, ok := <-sub.C:
+			if !ok {
+				if sub.Evicted() {
+					log.Println("[WS] evicted slow scoreboard client")
+					conn.Close(live.CloseTryAgainLate, "slow consumer")
+				} else {
+					conn.Close(live.CloseGoingAway, "")
+				}
+				<-done
+				return
+			}
+			if u.Match == nil {
+				continue
+			}
+			d := deltaOf(*u.Match, u.League)
+			d.Type = u.Type
+			d.ID = u.ID
+			d.Event = u.Event
+			if err := writeWS(conn, d); err != nil {
+				conn.Close(live.CloseGoingAway, "")
+				<-done
+				return
+			}
+		}
+	}
+}
+
+// sendSnapshot sends current scores of every scheduled, live or suspended match the filter selects
+func (h *ScoreboardHandler) sendSnapshot(parent context.Context, conn *live.WSConn, f scoreboardFilter) {
+	ctx, cancel := context.WithTimeout(parent, 8*time.Second)
+	defer cancel()
+
+	list, err := h.matches.ListByStatus(ctx, models.Scheduled, models.Live, models.Suspended)
+	if err != nil {
+		writeWS(conn, map[string]string{"type": "error", "error": "db error"})
+		return
+	}
+	// leagues are read fresh for every snapshot; updates carry their own
+	all, err := h.teams.List(ctx)
+	if err != nil {
+		writeWS(conn, map[string]string{"type": "error", "error": "db error"})
+		return
+	}
+	leagueOf := make(map[string]string, len(all))
+	for _, t := range all {
+		leagueOf[t.Code] = t.League
+	}
+
+	accept := buildScoreFilter(f)
+	out := make([]scoreDelta, 0)
+	for _, m := range list {
+		if !accept(live.Update{MatchKey: m.MatchKey, League: leagueOf[m.HomeCode], Match: &m}) {
+			continue
+		}
+		d := deltaOf(m, leagueOf[m.HomeCode])
+		d.Type = live.TypeSnapshot
+		out = append(out, d)
+	}
+	writeWS(conn, map[string]any{"type": "snapshot", "subscription": f, "matches": out})
+}
+
+// buildScoreFilter selects updates by match key, team, or the league the
+// update was published with.
+func buildScoreFilter(f scoreboardFilter) func(live.Update) bool {
+	leagues := toSet(f.Leagues, strings.ToLower)
+	teams := toSet(f.Teams, strings.ToUpper)
+	keys := toSet(f.Matches, nil)
+
+	return func(u live.Update) bool {
+		if len(leagues) == 0 && len(teams) == 0 && len(keys) == 0 {
+			return true
+		}
+		if keys[u.MatchKey] {
+			return true
+		}
+		if u.Match == nil {
+			return false
+		}
+		m := u.Match
+		if teams[m.HomeCode] || teams[m.AwayCode] {
+			return true
+		}
+		return leagues[strings.ToLower(u.League)]
+	}
+}
+
+func normalizeFilter(f scoreboardFilter) scoreboardFilter {
+	clean := func(in []string) []string {
+		out := make([]string, 0, len(in))
+		for _, s := range in {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return scoreboardFilter{Leagues: clean(f.Leagues), Teams: clean(f.Teams), Matches: clean(f.Matches)}
+}
+
+func deltaOf(m models.Match, league string) scoreDelta {
+	return scoreDelta{
+		MatchKey:  m.MatchKey,
+		League:    league,
+		HomeCode:  m.HomeCode,
+		AwayCode:  m.AwayCode,
+		HomeGoals: m.HomeGoals,
+		AwayGoals: m.AwayGoals,
+		Status:    m.Status,
+	}
+}
+
+func writeWS(conn *live.WSConn, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return conn.WriteText(b)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func toSet(list []string, norm func(string) string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		if norm != nil {
+			s = norm(s)
+		}
+		set[s] = true
+	}
+	return set
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/projection"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/standings"
+)
+
+type SeasonHandler struct {
+	seasons   repository.SeasonStore
+	teams     repository.TeamStore
+	matches   repository.MatchStore
+	rules     repository.RulesStore
+	projector *projection.Projector
+	events    chan<- models.EventLog
+}
+
+func NewSeasonHandler(seasons repository.SeasonStore, teams repository.TeamStore, matches repository.MatchStore, rules repository.RulesStore, projector *projection.Projector, events chan<- models.EventLog) *SeasonHandler {
+	return &SeasonHandler{seasons: seasons, teams: teams, matches: matches, rules: rules, projector: projector, events: events}
+}
+
+// GET /seasons?league=EPL
+// Lists seasons (oldest first) and the current season id per league.
+func (h *SeasonHandler) ListSeasons(w http.ResponseWriter, r *http.Request) {
+	league := strings.TrimSpace(r.URL.Query().Get("league"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	list, err := h.seasons.List(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+
+	current := map[string]string{}
+	for lg, ss := range byLeague(list) {
+		if s, ok := models.CurrentSeason(ss, time.Now()); ok {
+			current[lg] = s.ID
+		}
+	}
+	if list == nil {
+		list = []models.Season{}
+	}
+	writeJSON(w, 200, map[string]any{"seasons": list, "current": current, "count": len(list)})
+}
+
+// GET /seasons/{id}
+func (h *SeasonHandler) GetSeason(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	defer cancel()
+
+	s, found, err := h.seasons.Find(ctx, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "season not found"})
+		return
+	}
+	writeJSON(w, 200, s)
+}
+
+// POST /seasons
+// Body: {"league":"EPL","name":"2025-26","startDate":"2025-08-15","endDate":"2026-05-24","teams":["ARS",...]}
+// teams defaults to every team of the league. Matches of those teams without a
+// season that kick off within the dates are moved into the new season.
+func (h *SeasonHandler) CreateSeason(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		League    string   `json:"league"`
+		Name      string   `json:"name"`
+		StartDate string   `json:"startDate"` // YYYY-MM-DD or RFC3339
+		EndDate   string   `json:"endDate"`
+		Teams     []string `json:"teams"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	req.League = strings.TrimSpace(req.League)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.League == "" || req.Name == "" || req.StartDate == "" || req.EndDate == "" {
+		writeJSON(w, 400, map[string]string{"error": "league, name, startDate, endDate required"})
+		return
+	}
+	start, err1 := parseDay(req.StartDate)
+	end, err2 := parseDay(req.EndDate)
+	if err1 != nil || err2 != nil {
+		writeJSON(w, 400, map[string]string{"error": "startDate and endDate must be YYYY-MM-DD or RFC3339"})
+		return
+	}
+	if end.Before(start) {
+		writeJSON(w, 400, map[string]string{"error": "endDate must not be before startDate"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	leagueTeams, err := h.teams.ListByLeague(ctx, req.League)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if len(leagueTeams) == 0 {
+		writeJSON(w, 400, map[string]string{"error": "unknown league"})
+		return
+	}
+	inLeague := make(map[string]bool, len(leagueTeams))
+	for _, t := range leagueTeams {
+		inLeague[t.Code] = true
+	}
+
+	var teams []string
+	if len(req.Teams) == 0 {
+		for _, t := range leagueTeams {
+			teams = append(teams, t.Code)
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, c := range req.Teams {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			if !inLeague[c] {
+				writeJSON(w, 400, map[string]string{"error": "team " + c + " is not in " + req.League})
+				return
+			}
+			if !seen[c] {
+				seen[c] = true
+				teams = append(teams, c)
+			}
+		}
+	}
+	if len(teams) < 2 {
+		writeJSON(w, 400, map[string]string{"error": "a season needs at least 2 teams"})
+		return
+	}
+	sort.Strings(teams)
+
+	s := models.Season{
+		ID:        models.SeasonID(req.League, req.Name),
+		League:    req.League,
+		Name:      req.Name,
+		StartDate: start,
+		EndDate:   end,
+		Teams:     teams,
+		Status:    models.SeasonOpen,
+	}
+
+	existing, err := h.seasons.List(ctx, req.League)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	for _, o := range existing {
+		if o.ID == s.ID {
+			writeJSON(w, 409, map[string]string{"error": "season already exists"})
+			return
+		}
+		if o.Overlaps(s) {
+			writeJSON(w, 409, map[string]string{"error": "dates overlap season " + o.ID})
+			return
+		}
+	}
+
+	if err := h.seasons.Create(ctx, s); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]string{"error": "season already exists"})
+			return
+		}
+		writeJSON(w, 500, map[string]string{"error": "create error"})
+		return
+	}
+
+	adopted, err := h.matches.AssignSeason(ctx, s.ID, s.Teams, s.StartDate, s.EndDate.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "season created, assigning matches failed"})
+		return
+	}
+	if adopted > 0 {
+		// adopted results move from the league's season-less standings to the new season
+		for _, id := range []string{"", s.ID} {
+			if _, err := h.projector.Rebuild(ctx, s.League, id); err != nil {
+				sendLog(h.events, "standings_error", "", err.Error())
+			}
+		}
+	}
+
+	sendLog(h.events, "season_created", "", fmt.Sprintf("%s by %s, %d existing matches assigned", s.ID, actorID(r), adopted))
+	writeJSON(w, 201, map[string]any{"season": s, "assignedMatches": adopted})
+}
+
+// POST /seasons/{id}/close?force=true
+// Archives the final table and closes the season. Refused while matches are
+// still open (scheduled, live, postponed, ...) unless force=true.
+func (h *SeasonHandler) CloseSeason(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "true"
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	s, found, err := h.seasons.Find(ctx, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "season not found"})
+		return
+	}
+	if s.Status == models.SeasonClosed {
+		writeJSON(w, 409, map[string]string{"error": "season already closed"})
+		return
+	}
+
+	all, err := h.matches.List(ctx, s.ID)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	var open []string
+	for _, m := range all {
+		if !m.Status.Terminal() {
+			open = append(open, m.MatchKey)
+		}
+	}
+	if len(open) > 0 && !force {
+		writeJSON(w, 409, map[string]any{"error": "season has unfinished matches (use force=true to close anyway)", "matches": open})
+		return
+	}
+
+	teams, err := projection.SeasonTeams(ctx, h.teams, s)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	rules, err := leagueRules(ctx, h.rules, s.League)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	table := standings.Build(teams, all, rules)
+
+	by := actorID(r)
+	if err := h.seasons.Close(ctx, s.ID, time.Now().UTC(), by, table); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			writeJSON(w, 409, map[string]string{"error": "season already closed"})
+			return
+		}
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+		return
+	}
+
+	sendLog(h.events, "season_closed", "", fmt.Sprintf("%s by %s (%d unfinished matches)", s.ID, by, len(open)))
+	writeJSON(w, 200, map[string]any{"season": s.ID, "status": models.SeasonClosed, "table": table, "unfinished": len(open)})
+}
+
+// seasonScope is what a read (table, stats, matches) covers.
+type seasonScope struct {
+	IDs    []string       // season ids for the MatchStore filters; nil = everything
+	Season *models.Season // set when exactly one season is in scope
+}
+
+// ID is the single season in scope, or "".
+func (sc seasonScope) ID() string {
+	if sc.Season == nil {
+		return ""
+	}
+	return sc.Season.ID
+}
+
+// resolveSeason turns ?season= (with ?league=) into a scope.
+//   - season=all: no filter
+//   - season=<id>: that season (ErrNotFound if unknown or of another league)
+//   - no season: the current season of league, or of every league when league
+//     is empty. Leagues without seasons fall back to matches without a season,
+//     so data created before seasons existed stays visible.
+func resolveSeason(ctx context.Context, seasons repository.SeasonStore, teams repository.TeamStore, league, param string) (seasonScope, error) {
+	switch param {
+	case "all":
+		return seasonScope{}, nil
+	case "":
+	default:
+		s, found, err := seasons.Find(ctx, param)
+		if err != nil {
+			return seasonScope{}, err
+		}
+		if !found || (league != "" && s.League != league) {
+			return seasonScope{}, repository.ErrNotFound
+		}
+		return seasonScope{IDs: []string{s.ID}, Season: &s}, nil
+	}
+
+	list, err := seasons.List(ctx, league)
+	if err != nil {
+		return seasonScope{}, err
+	}
+	grouped := byLeague(list)
+
+	leagues := []string{league}
+	if league == "" {
+		all, err := teams.List(ctx)
+		if err != nil {
+			return seasonScope{}, err
+		}
+		seen := map[string]bool{}
+		leagues = nil
+		for _, t := range all {
+			if !seen[t.League] {
+				seen[t.League] = true
+				leagues = append(leagues, t.League)
+			}
+		}
+	}
+
+	var sc seasonScope
+	unassigned := false
+	now := time.Now()
+	for _, lg := range leagues {
+		s, ok := models.CurrentSeason(grouped[lg], now)
+		if !ok {
+			unassigned = true
+			continue
+		}
+		sc.IDs = append(sc.IDs, s.ID)
+		if league != "" {
+			sc.Season = &s
+		}
+	}
+	if len(sc.IDs) == 0 {
+		// no seasons set up at all: behave as before seasons existed
+		return seasonScope{}, nil
+	}
+	if unassigned {
+		sc.IDs = append(sc.IDs, "")
+	}
+	return sc, nil
+}
+
+// writeSeasonError answers a failed resolveSeason.
+func writeSeasonError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, 404, map[string]string{"error": "season not found"})
+		return
+	}
+	writeJSON(w, 500, map[string]string{"error": "db error"})
+}
+
+func byLeague(list []models.Season) map[string][]models.Season {
+	out := map[string][]models.Season{}
+	for _, s := range list {
+		out[s.League] = append(out[s.League], s)
+	}
+	return out
+}
+
+// parseDay accepts a calendar day (YYYY-MM-DD, taken as UTC midnight) or RFC3339.
+func parseDay(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t.UTC(), err
+}
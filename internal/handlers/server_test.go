@@ -0,0 +1,230 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"final-by-me/internal/auth"
+	"final-by-me/internal/handlers"
+	"final-by-me/internal/live"
+	"final-by-me/internal/middleware"
+	"final-by-me/internal/models"
+	"final-by-me/internal/projection"
+	"final-by-me/internal/repository/memory"
+	"final-by-me/internal/seed"
+)
+
+var testSecret = []byte("test-secret")
+
+// tokens signed with testSecret
+var (
+	adminToken = sign("admin")
+	userToken  = sign("user")
+)
+
+func sign(role string) string {
+	t, err := auth.Sign(testSecret, "000000000000000000000001", role+"@example.com", role, "", "")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// newServer wires the routes of main.go over the in-memory stores
+// (STORAGE=memory), admin routes behind the same middleware.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv, _ := newServerStores(t)
+	return srv
+}
+
+// testStores are the stores behind a test server that tests fill directly.
+type testStores struct {
+	seasons *memory.SeasonRepo
+	players *memory.PlayerRepo
+}
+
+// newServerStores is newServer that also hands out some of its stores.
+func newServerStores(t *testing.T) (*httptest.Server, testStores) {
+	t.Helper()
+	ctx := context.Background()
+
+	teams := memory.NewTeamRepo()
+	matches := memory.NewMatchRepo()
+	seasons := memory.NewSeasonRepo()
+	players := memory.NewPlayerRepo()
+	rules := memory.NewRulesRepo()
+	users := memory.NewUserRepo()
+	events := memory.NewEventRepo()
+	if err := teams.SeedIfEmpty(ctx, seed.TeamsAll()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rules.SeedIfEmpty(ctx, seed.Rules()); err != nil {
+		t.Fatal(err)
+	}
+	projector := projection.New(memory.NewStandingsRepo(), matches, teams, seasons, rules)
+	hub := live.NewHub(10)
+
+	authH := handlers.NewAuthHandler(users, testSecret, teams)
+	teamH := handlers.NewTeamHandler(teams, rules, projector)
+	matchH := handlers.NewMatchMongoHandler(matches, teams, seasons, players, rules, projector, nil, hub)
+	seasonH := handlers.NewSeasonHandler(seasons, teams, matches, rules, projector, nil)
+	tableH := handlers.NewTableHandler(teams, matches, seasons, rules, projector, hub)
+	statsH := handlers.NewStatsHandler(matches, teams, seasons)
+	playerH := handlers.NewPlayerHandler(players, teams, nil)
+	disciplineH := handlers.NewDisciplineHandler(matches, teams, seasons, rules)
+	calendarH := handlers.NewCalendarHandler(matches, teams, seasons)
+	adminH := handlers.NewAdminHandler(matches, events, projector)
+	boardH := handlers.NewScoreboardHandler(matches, teams, hub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../static/index.html")
+	})
+
+	mux.HandleFunc("POST /auth/register", authH.Register)
+	mux.HandleFunc("POST /auth/login", authH.Login)
+
+	mux.HandleFunc("GET /leagues", teamH.ListLeagues)
+	mux.HandleFunc("GET /leagues/{league}/rules", teamH.GetRules)
+	mux.HandleFunc("GET /teams", teamH.ListTeams)
+	mux.HandleFunc("GET /teams/{code}/positions", tableH.PositionHistory)
+	mux.HandleFunc("GET /teams/{code}/players", playerH.ListSquad)
+	mux.HandleFunc("GET /players/{id}", playerH.GetPlayer)
+
+	mux.HandleFunc("GET /seasons", seasonH.ListSeasons)
+	mux.HandleFunc("GET /seasons/{id}", seasonH.GetSeason)
+	mux.HandleFunc("GET /matches", matchH.ListMatches)
+	mux.HandleFunc("GET /matches/{key}", matchH.GetMatch)
+	mux.HandleFunc("GET /matches/{key}/stream", matchH.StreamMatch)
+	mux.HandleFunc("GET /ws/scoreboard", boardH.Connect)
+	mux.HandleFunc("GET /table", tableH.GetTable)
+	mux.HandleFunc("GET /table/stream", tableH.StreamTable)
+	mux.HandleFunc("GET /stats", statsH.GetStats)
+	mux.HandleFunc("GET /stats/scorers", statsH.TopScorers)
+	mux.HandleFunc("GET /stats/assists", statsH.TopAssists)
+	mux.HandleFunc("GET /discipline", disciplineH.GetDiscipline)
+	mux.HandleFunc("GET /calendar/teams/{file}", calendarH.TeamFeed)
+	mux.HandleFunc("GET /calendar/leagues/{file}", calendarH.LeagueFeed)
+
+	adminChain := func(h http.HandlerFunc) http.Handler {
+		return middleware.WithJSON(middleware.AuthJWT(testSecret)(middleware.RequireRole("admin")(h)))
+	}
+	admin := map[string]http.HandlerFunc{
+		"PUT /leagues/{league}/rules":        teamH.PutRules,
+		"PATCH /teams/{code}":                teamH.UpdateTeam,
+		"POST /leagues/{league}/fixtures":    matchH.GenerateFixtures,
+		"POST /teams/{code}/players":         playerH.AddPlayer,
+		"PATCH /teams/{code}/players/{id}":   playerH.UpdatePlayer,
+		"DELETE /teams/{code}/players/{id}":  playerH.ReleasePlayer,
+		"POST /seasons":                      seasonH.CreateSeason,
+		"POST /seasons/{id}/close":           seasonH.CloseSeason,
+		"POST /matches":                      matchH.CreateMatch,
+		"POST /matches/import":               matchH.ImportMatches,
+		"PATCH /matches/{key}/events":        matchH.AddEvent,
+		"PUT /matches/{key}/events/{id}":     matchH.UpdateEvent,
+		"DELETE /matches/{key}/events/{id}":  matchH.DeleteEvent,
+		"PATCH /matches/{key}/period":        matchH.SetPeriod,
+		"POST /matches/{key}/shootout/kicks": matchH.AddShootoutKick,
+		"PATCH /matches/{key}/status":        matchH.SetStatus,
+		"POST /matches/{key}/finalize":       matchH.Finalize,
+		"PATCH /matches/{key}":               matchH.UpdateMatch,
+		"POST /matches/{key}/postpone":       matchH.Postpone,
+		"POST /matches/{key}/reschedule":     matchH.Reschedule,
+		"POST /matches/{key}/resume":         matchH.Resume,
+		"POST /matches/{key}/replay":         matchH.Replay,
+		"POST /matches/{key}/award":          matchH.AwardMatch,
+		"POST /admin/scores/reconcile":       adminH.ReconcileScores,
+		"POST /admin/standings/rebuild":      adminH.RebuildStandings,
+		"POST /admin/standings/check":        adminH.CheckStandings,
+	}
+	if len(admin) != len(adminRoutes) {
+		t.Fatalf("%d admin handlers for %d admin routes", len(admin), len(adminRoutes))
+	}
+	for _, rt := range adminRoutes {
+		h, ok := admin[rt]
+		if !ok {
+			t.Fatalf("no handler for %s", rt)
+		}
+		mux.Handle(rt, adminChain(h))
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, testStores{seasons: seasons, players: players}
+}
+
+// adminRoutes are the patterns main.go puts behind the admin chain.
+var adminRoutes = []string{
+	"PUT /leagues/{league}/rules",
+	"PATCH /teams/{code}",
+	"POST /leagues/{league}/fixtures",
+	"POST /teams/{code}/players",
+	"PATCH /teams/{code}/players/{id}",
+	"DELETE /teams/{code}/players/{id}",
+	"POST /seasons",
+	"POST /seasons/{id}/close",
+	"POST /matches",
+	"POST /matches/import",
+	"PATCH /matches/{key}/events",
+	"PUT /matches/{key}/events/{id}",
+	"DELETE /matches/{key}/events/{id}",
+	"PATCH /matches/{key}/period",
+	"POST /matches/{key}/shootout/kicks",
+	"PATCH /matches/{key}/status",
+	"POST /matches/{key}/finalize",
+	"PATCH /matches/{key}",
+	"POST /matches/{key}/postpone",
+	"POST /matches/{key}/reschedule",
+	"POST /matches/{key}/resume",
+	"POST /matches/{key}/replay",
+	"POST /matches/{key}/award",
+	"POST /admin/scores/reconcile",
+	"POST /admin/standings/rebuild",
+	"POST /admin/standings/check",
+}
+
+// call sends body (if any) as an admin and decodes the JSON answer into out
+// (if any).
+func call(t *testing.T, srv *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	return callAs(t, srv, adminToken, method, path, body, out)
+}
+
+// callAs is call with the bearer token ("" for none).
+func callAs(t *testing.T, srv *httptest.Server, token, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+	return res.StatusCode
+}
+
+func createMatch(t *testing.T, srv *httptest.Server, home, away string) models.Match {
+	t.Helper()
+	var m models.Match
+	body := `{"homeCode":"` + home + `","awayCode":"` + away + `","dateTime":"2030-08-16T14:00:00Z"}`
+	if code := call(t, srv, "POST", "/matches", body, &m); code != 201 {
+		t.Fatalf("create %s-%s: status %d", home, away, code)
+	}
+	return m
+}
@@ -3,49 +3,124 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"final-by-me/internal/leaderboard"
+	"final-by-me/internal/models"
 	"final-by-me/internal/repository"
 )
 
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 200
+)
+
 type StatsHandler struct {
-	matches *repository.MatchRepo
+	matches repository.MatchStore
+	teams   repository.TeamStore
+	seasons repository.SeasonStore
 }
 
-func NewStatsHandler(matches *repository.MatchRepo) *StatsHandler {
-	return &StatsHandler{matches: matches}
+func NewStatsHandler(matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore) *StatsHandler {
+	return &StatsHandler{matches: matches, teams: teams, seasons: seasons}
 }
 
+// GET /stats?season=EPL-2025-26 (or ?league=EPL for its current season; season=all for everything)
+// Defaults to the current season of every league.
 func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	all, err := h.matches.List(ctx)
+	q := r.URL.Query()
+	scope, err := resolveSeason(ctx, h.seasons, h.teams, strings.TrimSpace(q.Get("league")), strings.TrimSpace(q.Get("season")))
 	if err != nil {
-		writeJSON(w, 500, map[string]string{"error": "db error"})
+		writeSeasonError(w, err)
 		return
 	}
 
-	finished, err := h.matches.ListFinished(ctx)
+	st, err := h.matches.Stats(ctx, scope.IDs...)
 	if err != nil {
 		writeJSON(w, 500, map[string]string{"error": "db error"})
 		return
 	}
 
-	totalGoals := 0
-	for _, m := range finished {
-		totalGoals += m.HomeGoals + m.AwayGoals
-	}
-
 	avg := 0.0
-	if len(finished) > 0 {
-		avg = float64(totalGoals) / float64(len(finished))
+	if st.Played > 0 {
+		avg = float64(st.Goals) / float64(st.Played)
 	}
 
 	writeJSON(w, 200, map[string]any{
-		"totalMatches":     len(all),
-		"finishedMatches":  len(finished),
-		"totalGoals":       totalGoals,
+		"season":           scope.ID(),
+		"totalMatches":     st.Total,
+		"finishedMatches":  st.Played,
+		"awardedMatches":   st.ByStatus[models.Awarded],
+		"byStatus":         st.ByStatus,
+		"totalGoals":       st.Goals,
 		"avgGoalsPerMatch": avg,
+		"goalsByType":      st.GoalsByType,
+		"ownGoals":         st.GoalsByType[models.GoalOwnGoal],
+		"penaltiesScored":  st.GoalsByType[models.GoalPenalty],
+		"penaltiesMissed":  st.PenaltiesMissed,
 	})
 }
+
+// GET /stats/scorers?league=EPL&season=EPL-2025-26&penalties=false&limit=20
+// Top scorers of the finished matches; season as for GET /matches.
+// Own goals never count; penalties=false leaves penalty goals out too.
+func (h *StatsHandler) TopScorers(w http.ResponseWriter, r *http.Request) {
+	h.leaderboard(w, r, "scorers", leaderboard.Scorers)
+}
+
+// GET /stats/assists?league=EPL&season=EPL-2025-26&limit=20
+// Top assist providers of the finished matches; season as for GET /matches.
+func (h *StatsHandler) TopAssists(w http.ResponseWriter, r *http.Request) {
+	h.leaderboard(w, r, "assists", leaderboard.Assists)
+}
+
+func (h *StatsHandler) leaderboard(w http.ResponseWriter, r *http.Request, name string, rank func([]leaderboard.Entry) []leaderboard.Entry) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	p := r.URL.Query()
+	q := models.MatchQuery{Statuses: []models.MatchStatus{models.Finished}}
+	scope, msg, err := leagueQuery(ctx, h.seasons, h.teams, p, &q)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+
+	var opts leaderboard.Options
+	switch strings.TrimSpace(p.Get("penalties")) {
+	case "", "true":
+	case "false":
+		opts.NoPenalties = true
+	default:
+		writeJSON(w, 400, map[string]string{"error": "penalties must be true|false"})
+		return
+	}
+	limit := defaultLeaderboardLimit
+	if v := strings.TrimSpace(p.Get("limit")); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxLeaderboardLimit {
+			writeJSON(w, 400, map[string]string{"error": "limit must be 1.." + strconv.Itoa(maxLeaderboardLimit)})
+			return
+		}
+	}
+
+	totals, err := h.matches.PlayerTotals(ctx, q)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	board := rank(leaderboard.Entries(totals, opts))
+	total := len(board)
+	if len(board) > limit {
+		board = board[:limit]
+	}
+	writeJSON(w, 200, map[string]any{"season": scope.ID(), name: board, "count": len(board), "total": total})
+}
@@ -2,160 +2,413 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"final-by-me/internal/live"
+	"final-by-me/internal/models"
+	"final-by-me/internal/projection"
 	"final-by-me/internal/repository"
+	"final-by-me/internal/standings"
 )
 
-type TableRow struct {
-	TeamCode   string `json:"teamCode"`
-	TeamName   string `json:"teamName"`
-	League     string `json:"league"`
-	IsFavorite bool   `json:"isFavorite"`
-
-	P   int `json:"played"`
-	W   int `json:"wins"`
-	D   int `json:"draws"`
-	L   int `json:"losses"`
-	GF  int `json:"goalsFor"`
-	GA  int `json:"goalsAgainst"`
-	GD  int `json:"goalDiff"`
-	Pts int `json:"points"`
-}
-
 type TableHandler struct {
-	teams   *repository.TeamRepo
-	matches *repository.MatchRepo
+	teams     repository.TeamStore
+	matches   repository.MatchStore
+	seasons   repository.SeasonStore
+	rules     repository.RulesStore
+	projector *projection.Projector
+	hub       *live.Hub
 }
 
-func NewTableHandler(teams *repository.TeamRepo, matches *repository.MatchRepo) *TableHandler {
-	return &TableHandler{teams: teams, matches: matches}
+func NewTableHandler(teams repository.TeamStore, matches repository.MatchStore, seasons repository.SeasonStore, rules repository.RulesStore, projector *projection.Projector, hub *live.Hub) *TableHandler {
+	return &TableHandler{teams: teams, matches: matches, seasons: seasons, rules: rules, projector: projector, hub: hub}
 }
 
-// GET /table?league=EPL&favorite=ARS
+// GET /table?league=EPL&season=EPL-2025-26&favorite=ARS
 // If league is provided -> table for that league only.
+// season defaults to the current season (season=all: every match ever stored);
+// a closed season returns its archived final table.
+// asOf=<RFC3339> and/or matchday=<n> give the table at that point instead.
+// view=home|away counts only home/away matches (default overall).
+// live=true folds in the current score of live matches ("as it stands") and
+// marks each row with its provisional change in position and points.
+// Each row carries the form guide (last five) and current streaks.
+// Points and ordering follow the league's rules (GET /leagues/{league}/rules).
 // Favorite is optional -> marks that team row as isFavorite=true.
+// Results are read from the standings projection; verify=true also recomputes
+// the table from the matches and reports whether both agree ("consistent").
 func (h *TableHandler) GetTable(w http.ResponseWriter, r *http.Request) {
-	league := strings.TrimSpace(r.URL.Query().Get("league"))
-	fav := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("favorite")))
+	tq, msg := parseTableQuery(r)
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 12*time.Second)
 	defer cancel()
 
-	// Load teams (by league if specified)
-	var teamsList []struct {
-		Code   string
-		Name   string
-		League string
+	scope, err := resolveSeason(ctx, h.seasons, h.teams, tq.league, tq.season)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
 	}
 
-	if league == "" {
-		all, err := h.teams.List(ctx)
-		if err != nil {
-			writeJSON(w, 500, map[string]string{"error": "db error"})
-			return
+	resp, err := h.table(ctx, tq, scope)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, 200, resp)
+}
+
+// GET /table/stream?league=EPL (same parameters as GET /table; live is implied)
+// Server-Sent Events: a "table" event with the live table on connect and again
+// after every change to a match in scope (goal, status, ...).
+func (h *TableHandler) StreamTable(w http.ResponseWriter, r *http.Request) {
+	tq, msg := parseTableQuery(r)
+	if msg == "" && tq.historical() {
+		msg = "asOf and matchday are not supported on the stream"
+	}
+	if msg != "" {
+		writeJSON(w, 400, map[string]string{"error": msg})
+		return
+	}
+	tq.live = true
+
+	flusher, ok := w.(http.Flusher)
+	if !ok || h.hub == nil {
+		writeJSON(w, 500, map[string]string{"error": "streaming not supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+	scope, err := resolveSeason(ctx, h.seasons, h.teams, tq.league, tq.season)
+	var inLeague map[string]bool
+	if err == nil && tq.league != "" {
+		var teams []models.Team
+		teams, err = h.teams.ListByLeague(ctx, tq.league)
+		inLeague = make(map[string]bool, len(teams))
+		for _, t := range teams {
+			inLeague[t.Code] = true
 		}
-		for _, t := range all {
-			teamsList = append(teamsList, struct {
-				Code, Name, League string
-			}{t.Code, t.Name, t.League})
+	}
+	cancel()
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	seasons := toSet(scope.IDs, nil)
+
+	sub := h.hub.Subscribe(16, func(u live.Update) bool {
+		m := u.Match
+		if m == nil {
+			return false
 		}
-	} else {
-		list, err := h.teams.ListByLeague(ctx, league)
+		if scope.IDs != nil && !seasons[m.Season] {
+			return false
+		}
+		return inLeague == nil || inLeague[m.HomeCode]
+	})
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func() error {
+		ctx, cancel := context.WithTimeout(r.Context(), 12*time.Second)
+		defer cancel()
+		resp, err := h.table(ctx, tq, scope)
 		if err != nil {
-			writeJSON(w, 500, map[string]string{"error": "db error"})
-			return
+			return err
 		}
-		for _, t := range list {
-			teamsList = append(teamsList, struct {
-				Code, Name, League string
-			}{t.Code, t.Name, t.League})
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
 		}
-	}
-
-	// Build rows
-	rows := make(map[string]*TableRow, len(teamsList))
-	leagueSet := make(map[string]bool, len(teamsList))
-	for _, t := range teamsList {
-		leagueSet[t.Code] = true
-		rows[t.Code] = &TableRow{
-			TeamCode:   t.Code,
-			TeamName:   t.Name,
-			League:     t.League,
-			IsFavorite: (fav != "" && t.Code == fav),
+		if _, err := fmt.Fprintf(w, "event: table\ndata: %s\n\n", data); err != nil {
+			return err
 		}
+		flusher.Flush()
+		return nil
 	}
-
-	// Finished matches
-	finished, err := h.matches.ListFinished(ctx)
-	if err != nil {
-		writeJSON(w, 500, map[string]string{"error": "db error"})
+	if send() != nil {
 		return
 	}
 
-	for _, m := range finished {
-		// If league specified: only count matches where BOTH teams are in this league
-		if league != "" {
-			if !leagueSet[m.HomeCode] || !leagueSet[m.AwayCode] {
-				continue
+	ping := time.NewTicker(25 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
 			}
-		}
+			flusher.Flush()
 
-		home := rows[m.HomeCode]
-		away := rows[m.AwayCode]
-		if home == nil || away == nil {
-			continue
+		case _, ok := <-sub.C:
+			if !ok {
+				// evicted as slow consumer; the client reconnects and gets a fresh table
+				return
+			}
+			// a burst of updates (goal + status) is one recompute
+			for drained := false; !drained; {
+				select {
+				case _, ok := <-sub.C:
+					if !ok {
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			if send() != nil {
+				return
+			}
 		}
+	}
+}
+
+// tableQuery holds the GET /table parameters.
+type tableQuery struct {
+	league   string
+	season   string
+	fav      string
+	view     string
+	asOf     time.Time
+	matchday int
+	live     bool
+	verify   bool
+}
 
-		home.P++
-		away.P++
+func (tq tableQuery) historical() bool {
+	return !tq.asOf.IsZero() || tq.matchday > 0
+}
 
-		home.GF += m.HomeGoals
-		home.GA += m.AwayGoals
-		away.GF += m.AwayGoals
-		away.GA += m.HomeGoals
+// parseTableQuery reads the table parameters; msg is set for a bad request.
+func parseTableQuery(r *http.Request) (tq tableQuery, msg string) {
+	q := r.URL.Query()
+	tq.league = strings.TrimSpace(q.Get("league"))
+	tq.season = strings.TrimSpace(q.Get("season"))
+	tq.fav = strings.ToUpper(strings.TrimSpace(q.Get("favorite")))
+	tq.live = q.Get("live") == "true"
+	tq.verify = q.Get("verify") == "true"
 
-		if m.HomeGoals > m.AwayGoals {
-			home.W++
-			away.L++
-			home.Pts += 3
-		} else if m.HomeGoals < m.AwayGoals {
-			away.W++
-			home.L++
-			away.Pts += 3
-		} else {
-			home.D++
-			away.D++
-			home.Pts++
-			away.Pts++
+	if v := strings.TrimSpace(q.Get("asOf")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return tq, "asOf must be RFC3339"
+		}
+		tq.asOf = t
+	}
+	if v := strings.TrimSpace(q.Get("matchday")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return tq, "matchday must be >= 1"
 		}
+		tq.matchday = n
+	}
+	if tq.live && tq.historical() {
+		return tq, "live cannot be combined with asOf or matchday"
 	}
 
-	out := make([]TableRow, 0, len(rows))
-	for _, r := range rows {
-		r.GD = r.GF - r.GA
-		out = append(out, *r)
+	tq.view = strings.ToLower(strings.TrimSpace(q.Get("view")))
+	switch tq.view {
+	case "":
+		tq.view = models.ViewOverall
+	case models.ViewOverall, models.ViewHome, models.ViewAway:
+	default:
+		return tq, "view must be overall|home|away"
 	}
+	return tq, ""
+}
 
-	sort.Slice(out, func(i, j int) bool {
-		if out[i].Pts != out[j].Pts {
-			return out[i].Pts > out[j].Pts
+// table computes the GET /table response for tq within scope.
+func (h *TableHandler) table(ctx context.Context, tq tableQuery, scope seasonScope) (map[string]any, error) {
+	var out []models.TableRow
+	liveCount := 0
+	consistent := true
+	if s := scope.Season; s != nil && s.Status == models.SeasonClosed && s.FinalTable != nil && !tq.historical() && tq.view == models.ViewOverall {
+		out = append(out, s.FinalTable...)
+	} else {
+		in, err := h.load(ctx, tq.league, scope)
+		if err != nil {
+			return nil, err
 		}
-		if out[i].GD != out[j].GD {
-			return out[i].GD > out[j].GD
+		lines := in.lines
+		if tq.historical() {
+			lines = standings.Until(lines, tq.asOf, tq.matchday)
 		}
-		if out[i].GF != out[j].GF {
-			return out[i].GF > out[j].GF
+
+		// the stored table of the season serves the plain overall view
+		stored := false
+		if s := scope.Season; s != nil && !tq.historical() && tq.view == models.ViewOverall {
+			var rows []models.TableRow
+			if rows, stored, err = h.projector.Table(ctx, s.League, s.ID, in.rules); err != nil {
+				return nil, err
+			}
+			out = append(out, rows...)
+		}
+		if !stored {
+			out = standings.BuildLines(in.teams, lines, in.rules, tq.view)
 		}
-		return out[i].TeamName < out[j].TeamName
-	})
 
-	writeJSON(w, 200, map[string]any{
-		"league": league,
+		if tq.verify {
+			all, err := h.matches.ResultLines(ctx, scope.IDs...)
+			if err != nil {
+				return nil, err
+			}
+			if tq.historical() {
+				all = standings.Until(all, tq.asOf, tq.matchday)
+			}
+			consistent = projection.SameRows(out, standings.BuildLines(in.teams, all, in.rules, tq.view))
+		}
+
+		if tq.live {
+			live, err := h.matches.ListByStatus(ctx, models.Live)
+			if err != nil {
+				return nil, err
+			}
+			live = inScope(live, scope)
+			liveCount = len(live)
+			provisional := standings.BuildLines(in.teams, append(lines, standings.AsItStands(live)...), in.rules, tq.view)
+			out = standings.Provisional(out, provisional, live)
+		}
+	}
+
+	for i := range out {
+		out[i].IsFavorite = tq.fav != "" && out[i].TeamCode == tq.fav
+	}
+
+	resp := map[string]any{
+		"league": tq.league,
+		"season": scope.ID(),
+		"view":   tq.view,
 		"count":  len(out),
 		"table":  out,
+	}
+	if !tq.asOf.IsZero() {
+		resp["asOf"] = tq.asOf
+	}
+	if tq.matchday > 0 {
+		resp["matchday"] = tq.matchday
+	}
+	if tq.live {
+		resp["live"] = true
+		resp["liveMatches"] = liveCount
+	}
+	if tq.verify {
+		resp["consistent"] = consistent
+	}
+	return resp, nil
+}
+
+// inScope keeps the matches of the seasons in scope.
+func inScope(matches []models.Match, scope seasonScope) []models.Match {
+	if scope.IDs == nil {
+		return matches
+	}
+	ids := toSet(scope.IDs, nil)
+	var out []models.Match
+	for _, m := range matches {
+		if ids[m.Season] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// GET /teams/{code}/positions?season=EPL-2025-26
+// The team's league position after every matchday of the season (default: current).
+func (h *TableHandler) PositionHistory(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(strings.TrimSpace(r.PathValue("code")))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 12*time.Second)
+	defer cancel()
+
+	team, found, err := h.teams.Find(ctx, code)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "team not found"})
+		return
+	}
+
+	scope, err := resolveSeason(ctx, h.seasons, h.teams, team.League, strings.TrimSpace(r.URL.Query().Get("season")))
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	if scope.Season != nil && !scope.Season.HasTeam(code) {
+		writeJSON(w, 404, map[string]string{"error": "team does not take part in season " + scope.Season.ID})
+		return
+	}
+
+	in, err := h.load(ctx, team.League, scope)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+
+	history := standings.History(code, in.teams, in.lines, in.rules)
+	writeJSON(w, 200, map[string]any{
+		"teamCode":  code,
+		"league":    team.League,
+		"season":    scope.ID(),
+		"count":     len(history),
+		"positions": history,
 	})
 }
+
+// tableInput is what a league table is computed from.
+type tableInput struct {
+	teams []models.Team
+	lines []models.ResultLine
+	rules models.LeagueRules
+}
+
+// load reads the rows, results and rules of a table for league within scope.
+func (h *TableHandler) load(ctx context.Context, league string, scope seasonScope) (tableInput, error) {
+	var in tableInput
+	var err error
+
+	switch {
+	case scope.Season != nil:
+		in.teams, err = projection.SeasonTeams(ctx, h.teams, *scope.Season)
+	case league != "":
+		in.teams, err = h.teams.ListByLeague(ctx, league)
+	default:
+		in.teams, err = h.teams.List(ctx)
+	}
+	if err != nil {
+		return in, err
+	}
+
+	// Finished and awarded matches from the standings projection; postponed,
+	// abandoned and cancelled ones do not count. With a league, only matches
+	// where BOTH teams are in it count.
+	if in.lines, err = h.projector.Lines(ctx, league, scope.IDs...); err != nil {
+		return in, err
+	}
+
+	// the league's rules score the table; a table across leagues uses the defaults
+	rulesLeague := league
+	if scope.Season != nil {
+		rulesLeague = scope.Season.League
+	}
+	in.rules, err = leagueRules(ctx, h.rules, rulesLeague)
+	return in, err
+}
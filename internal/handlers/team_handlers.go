@@ -2,20 +2,25 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 
+	"final-by-me/internal/models"
+	"final-by-me/internal/projection"
 	"final-by-me/internal/repository"
 	"final-by-me/internal/seed"
 )
 
 type TeamHandler struct {
-	teams *repository.TeamRepo
+	teams     repository.TeamStore
+	rules     repository.RulesStore
+	projector *projection.Projector
 }
 
-func NewTeamHandler(teams *repository.TeamRepo) *TeamHandler {
-	return &TeamHandler{teams: teams}
+func NewTeamHandler(teams repository.TeamStore, rules repository.RulesStore, projector *projection.Projector) *TeamHandler {
+	return &TeamHandler{teams: teams, rules: rules, projector: projector}
 }
 
 // GET /teams?league=EPL
@@ -42,7 +47,140 @@ func (h *TeamHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, map[string]any{"teams": list})
 }
 
+// PATCH /teams/{code}
+// Body: {"name":"Arsenal FC"}
+// Renames the team; stored standings that list it are updated.
+func (h *TeamHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeJSON(w, 400, map[string]string{"error": "name required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	t, found, err := h.teams.Find(ctx, strings.ToUpper(strings.TrimSpace(r.PathValue("code"))))
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if !found {
+		writeJSON(w, 404, map[string]string{"error": "team not found"})
+		return
+	}
+	t.Name = req.Name
+	if err := h.teams.Update(ctx, t); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+		return
+	}
+	if err := h.projector.UpdateTeam(ctx, t); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "team saved, updating standings failed"})
+		return
+	}
+	writeJSON(w, 200, t)
+}
+
 // GET /leagues
 func (h *TeamHandler) ListLeagues(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, map[string]any{"leagues": seed.Leagues()})
 }
+
+// GET /leagues/{league}/rules
+// Points, tie-breakers and bonus schemes used for the league table.
+func (h *TeamHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	league := strings.TrimSpace(r.PathValue("league"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teams, err := h.teams.ListByLeague(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if len(teams) == 0 {
+		writeJSON(w, 404, map[string]string{"error": "unknown league"})
+		return
+	}
+
+	rules, err := leagueRules(ctx, h.rules, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	writeJSON(w, 200, rules)
+}
+
+// PUT /leagues/{league}/rules
+// Body: {"pointsWin":3,"pointsDraw":1,"pointsLoss":0,"tieBreakers":["h2h_points","goal_difference"],"bonuses":[{"kind":"goals_scored","threshold":4,"points":1}]}
+// tieBreakers: goal_difference | goals_for | h2h_points | h2h_goal_difference | away_goals | wins | fair_play
+// bonuses: goals_scored (threshold) | clean_sheet | narrow_loss (threshold) | shootout_win
+// Replaces the rules and rescores the stored standings of the league.
+func (h *TeamHandler) PutRules(w http.ResponseWriter, r *http.Request) {
+	league := strings.TrimSpace(r.PathValue("league"))
+
+	var req models.LeagueRules
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, 400, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if req.TieBreakers == nil {
+		req.TieBreakers = []models.TieBreaker{}
+	}
+	if err := req.Validate(); err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	teams, err := h.teams.ListByLeague(ctx, league)
+	if err != nil {
+		writeJSON(w, 500, map[string]string{"error": "db error"})
+		return
+	}
+	if len(teams) == 0 {
+		writeJSON(w, 404, map[string]string{"error": "unknown league"})
+		return
+	}
+
+	req.League = league
+	req.UpdatedAt = time.Now().UTC()
+	req.UpdatedBy = actorID(r)
+	if err := h.rules.Upsert(ctx, req); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "update error"})
+		return
+	}
+	if err := h.projector.Rescore(ctx, league); err != nil {
+		writeJSON(w, 500, map[string]string{"error": "rules saved, rescoring standings failed"})
+		return
+	}
+	writeJSON(w, 200, req)
+}
+
+// leagueRules returns the stored rules of league, or the defaults. The
+// discipline is filled in, so GET /leagues/{league}/rules shows the bans in force.
+func leagueRules(ctx context.Context, store repository.RulesStore, league string) (models.LeagueRules, error) {
+	rules := models.DefaultRules(league)
+	if league != "" {
+		stored, found, err := store.Find(ctx, league)
+		if err != nil {
+			return models.LeagueRules{}, err
+		}
+		if found {
+			rules = stored
+		}
+	}
+	d := rules.DisciplineRules()
+	rules.Discipline = &d
+	return rules, nil
+}
@@ -0,0 +1,324 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"final-by-me/internal/fixtures"
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/squad"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Outcome of one row.
+const (
+	Created   = "created"   // inserted
+	Valid     = "valid"     // dry run: would be inserted
+	Updated   = "updated"   // a stored fixture without events got the result
+	Updatable = "updatable" // dry run: would be updated
+	Exists    = "exists"    // imported before, left as it is
+	Invalid   = "invalid"
+)
+
+type Result struct {
+	Row      int    `json:"row"`
+	MatchKey string `json:"matchKey,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	DryRun   bool     `json:"dryRun"`
+	Rows     int      `json:"rows"`
+	Created  int      `json:"created"` // dry run: rows that would be created
+	Updated  int      `json:"updated"` // dry run: rows that would be updated
+	Existing int      `json:"existing"`
+	Invalid  int      `json:"invalid"`
+	Results  []Result `json:"results"`
+
+	Matches []models.Match `json:"-"` // the inserted and updated matches
+}
+
+// Import validates rows against the stored teams and seasons and inserts the
+// valid ones that are not stored yet. The match key is derived from the teams
+// and the kick-off, so importing the same rows again creates nothing.
+// Rows with a score (or events) are stored as finished; the goal events, if
+// any, must add up to the score. A result for a stored fixture that is still
+// scheduled and has no events (e.g. a generated one) is written onto it.
+// With dryRun nothing is written.
+func Import(ctx context.Context, teams repository.TeamStore, seasons repository.SeasonStore, players repository.PlayerStore, matches repository.MatchStore, rows []Row, dryRun bool, actor string) (Report, error) {
+	rep := Report{DryRun: dryRun, Rows: len(rows), Results: make([]Result, len(rows))}
+
+	all, err := teams.List(ctx)
+	if err != nil {
+		return rep, err
+	}
+	v := validator{seasons: seasons, players: players, byName: map[string]models.Team{}, leagues: map[string][]models.Season{}, actor: actor}
+	for _, t := range all {
+		v.byName[strings.ToUpper(t.Code)] = t
+		v.byName[strings.ToUpper(t.Name)] = t
+	}
+
+	var valid []models.Match
+	var at []int // index in rows of valid[i]
+	firstRow := map[string]int{}
+	for i, row := range rows {
+		res := &rep.Results[i]
+		res.Row = row.Row
+		m, msg, err := v.match(ctx, row)
+		if err != nil {
+			return rep, err
+		}
+		res.MatchKey = m.MatchKey
+		if msg == "" {
+			if r, dup := firstRow[m.MatchKey]; dup {
+				msg = fmt.Sprintf("same match as row %d", r)
+			}
+		}
+		if msg != "" {
+			res.Status, res.Error = Invalid, msg
+			continue
+		}
+		firstRow[m.MatchKey] = row.Row
+		valid = append(valid, m)
+		at = append(at, i)
+	}
+
+	keys := make([]string, len(valid))
+	for i, m := range valid {
+		keys[i] = m.MatchKey
+	}
+	taken, err := matches.ExistingKeys(ctx, keys)
+	if err != nil {
+		return rep, err
+	}
+
+	status, fillStatus := Created, Updated
+	if dryRun {
+		status, fillStatus = Valid, Updatable
+	}
+	var fills []fill
+	for i, m := range valid {
+		res := &rep.Results[at[i]]
+		if !taken[m.MatchKey] {
+			res.Status = status
+			rep.Matches = append(rep.Matches, m)
+			continue
+		}
+		res.Status = Exists
+		if !m.Status.HasResult() {
+			continue
+		}
+		stored, found, err := matches.FindByKey(ctx, m.MatchKey)
+		if err != nil {
+			return rep, err
+		}
+		if !found || stored.Status != models.Scheduled || len(stored.Events) > 0 {
+			continue
+		}
+		if stored.Knockout && m.Level() {
+			res.Status, res.Error = Invalid, "the stored fixture is a knockout match: a level result needs a shootout"
+			continue
+		}
+		res.Status = fillStatus
+		fills = append(fills, fill{at: at[i], fixture: stored, result: m})
+	}
+
+	if !dryRun && len(rep.Matches) > 0 {
+		if _, err := matches.CreateMany(ctx, rep.Matches); err != nil {
+			// a concurrent import took a key: nothing of this batch was stored
+			rep.Matches = nil
+			return rep, err
+		}
+	}
+	if !dryRun {
+		for _, f := range fills {
+			m, err := f.apply(ctx, matches, v.actor)
+			if errors.Is(err, repository.ErrConflict) {
+				rep.Results[f.at].Status = Exists // kicked off or edited meanwhile
+				continue
+			}
+			if err != nil {
+				return rep, err
+			}
+			rep.Matches = append(rep.Matches, m)
+		}
+	}
+
+	for _, res := range rep.Results {
+		switch res.Status {
+		case Created, Valid:
+			rep.Created++
+		case Updated, Updatable:
+			rep.Updated++
+		case Exists:
+			rep.Existing++
+		case Invalid:
+			rep.Invalid++
+		}
+	}
+	return rep, nil
+}
+
+// fill is an imported result for a stored fixture.
+type fill struct {
+	at      int // index in rows
+	fixture models.Match
+	result  models.Match
+}
+
+// apply writes the result onto the fixture, which must still be scheduled and
+// without events, and returns the updated match.
+func (f fill) apply(ctx context.Context, matches repository.MatchStore, actor string) (models.Match, error) {
+	change := models.StatusChange{From: models.Scheduled, To: models.Finished, At: time.Now().UTC(), By: actor, Reason: "imported result"}
+	if err := matches.FillResult(ctx, f.fixture.MatchKey, f.result, change); err != nil {
+		return models.Match{}, err
+	}
+	m := f.fixture
+	m.Events, m.HomeGoals, m.AwayGoals, m.ScoreOnly = f.result.Events, f.result.HomeGoals, f.result.AwayGoals, f.result.ScoreOnly
+	m.Stamp(change)
+	return m, nil
+}
+
+type validator struct {
+	seasons repository.SeasonStore
+	players repository.PlayerStore
+	byName  map[string]models.Team     // upper-case code and name
+	leagues map[string][]models.Season // loaded on first use
+	actor   string
+}
+
+// match turns row into the match to store; msg explains why it cannot be.
+func (v *validator) match(ctx context.Context, row Row) (m models.Match, msg string, err error) {
+	if row.problem != "" {
+		return m, row.problem, nil
+	}
+
+	home, ok := v.byName[strings.ToUpper(strings.TrimSpace(row.HomeCode))]
+	if !ok {
+		return m, fmt.Sprintf("unknown home team %q", row.HomeCode), nil
+	}
+	away, ok := v.byName[strings.ToUpper(strings.TrimSpace(row.AwayCode))]
+	if !ok {
+		return m, fmt.Sprintf("unknown away team %q", row.AwayCode), nil
+	}
+	if home.Code == away.Code {
+		return m, "home and away team must differ", nil
+	}
+	dt, err := time.Parse(time.RFC3339, strings.TrimSpace(row.DateTime))
+	if err != nil {
+		return m, "dateTime must be RFC3339", nil
+	}
+	dt = dt.UTC()
+	if row.Matchday < 0 {
+		return m, "matchday must be >= 1 (0 or omitted for none)", nil
+	}
+
+	m = models.Match{
+		MatchKey: fixtures.MatchKey(home.Code, away.Code, dt),
+		Matchday: row.Matchday,
+		DateTime: dt,
+		HomeCode: home.Code,
+		AwayCode: away.Code,
+		Status:   models.Scheduled,
+		Knockout: row.Knockout,
+		Events:   []models.MatchEvent{},
+	}
+
+	if m.Season, msg, err = v.season(ctx, strings.TrimSpace(row.Season), home, away.Code, dt); err != nil || msg != "" {
+		return m, msg, err
+	}
+
+	msg, err = v.result(ctx, &m, row)
+	return m, msg, err
+}
+
+// result adds the score and events of row to m. Player ids are checked
+// against the squads; free-text names are kept (historical data).
+func (v *validator) result(ctx context.Context, m *models.Match, row Row) (string, error) {
+	if (row.HomeGoals == nil) != (row.AwayGoals == nil) {
+		return "homeGoals and awayGoals go together", nil
+	}
+	if row.HomeGoals == nil && len(row.Events) == 0 {
+		return "", nil
+	}
+
+	now := time.Now().UTC()
+	for _, e := range row.Events {
+		if msg := e.Normalize(); msg != "" {
+			return msg, nil
+		}
+		if e.TeamCode != m.HomeCode && e.TeamCode != m.AwayCode {
+			return "event teamCode " + e.TeamCode + " is not playing in this match", nil
+		}
+		if err := models.CheckEventTime(models.PeriodNotStarted, e.Minute, e.Stoppage); err != nil {
+			return err.Error(), nil
+		}
+		if msg, err := squad.ResolveEvent(ctx, v.players, &e, nil, true); err != nil || msg != "" {
+			return msg, err
+		}
+		e.ID = primitive.NewObjectID().Hex()
+		e.CreatedAt = now
+		e.CreatedBy = v.actor
+		m.Events = append(m.Events, e)
+	}
+
+	m.Status = models.Finished
+	m.HomeGoals, m.AwayGoals = m.ScoreFromEvents()
+	goals := m.HomeGoals+m.AwayGoals > 0
+	if row.HomeGoals != nil {
+		hg, ag := *row.HomeGoals, *row.AwayGoals
+		if hg < 0 || ag < 0 {
+			return "goals must be >= 0", nil
+		}
+		if goals && (hg != m.HomeGoals || ag != m.AwayGoals) {
+			return fmt.Sprintf("score %d-%d does not match the goal events (%d-%d)", hg, ag, m.HomeGoals, m.AwayGoals), nil
+		}
+		m.ScoreOnly = !goals && hg+ag > 0
+		m.HomeGoals, m.AwayGoals = hg, ag
+	}
+	if m.Knockout && m.Level() {
+		return "a level knockout result needs a shootout; import it without the score and record the shootout on the match", nil
+	}
+	return "", nil
+}
+
+// season picks the season of a match the way POST /matches does: the given
+// one, else the season of the home league covering dt ("" for leagues
+// without seasons).
+func (v *validator) season(ctx context.Context, id string, home models.Team, away string, dt time.Time) (string, string, error) {
+	list, ok := v.leagues[home.League]
+	if !ok {
+		var err error
+		if list, err = v.seasons.List(ctx, home.League); err != nil {
+			return "", "", err
+		}
+		v.leagues[home.League] = list
+	}
+
+	var s *models.Season
+	for i := range list {
+		if (id != "" && list[i].ID == id) || (id == "" && list[i].Contains(dt)) {
+			s = &list[i]
+			break
+		}
+	}
+	switch {
+	case s == nil && id != "":
+		return "", "season " + id + " not found in " + home.League, nil
+	case s == nil && len(list) > 0:
+		return "", "no " + home.League + " season covers dateTime; pass season", nil
+	case s == nil:
+		return "", "", nil
+	case s.Status != models.SeasonOpen:
+		return "", "season " + s.ID + " is closed", nil
+	case !s.HasTeam(home.Code) || !s.HasTeam(away):
+		return "", "both teams must take part in season " + s.ID, nil
+	}
+	return s.ID, "", nil
+}
@@ -0,0 +1,186 @@
+// Package importer loads fixtures and results in bulk from CSV or JSON.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+// DefaultKickoff is the kick-off time of CSV rows without a Time column
+// (older football-data seasons).
+const DefaultKickoff = "15:00"
+
+// Row is one match to import.
+type Row struct {
+	Row       int                 `json:"-"`        // CSV line, or 1-based position in a JSON array
+	DateTime  string              `json:"dateTime"` // RFC3339
+	HomeCode  string              `json:"homeCode"` // team code or name
+	AwayCode  string              `json:"awayCode"`
+	HomeGoals *int                `json:"homeGoals"` // final score; both or neither
+	AwayGoals *int                `json:"awayGoals"`
+	Season    string              `json:"season"`   // optional: defaults to the home league's season covering dateTime
+	Matchday  int                 `json:"matchday"` // optional league round; 0 for none
+	Knockout  bool                `json:"knockout"`
+	Events    []models.MatchEvent `json:"events"`
+
+	problem string // set when the input row could not be read
+}
+
+// ParseJSON reads a JSON array of rows.
+func ParseJSON(r io.Reader) ([]Row, error) {
+	var rows []Row
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, errors.New("body must be a JSON array of matches")
+	}
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+	return rows, nil
+}
+
+// csvColumns maps the accepted headers to Row fields. The football-data
+// layout (Date, Time, HomeTeam, AwayTeam, FTHG, FTAG; HG/AG in older files)
+// is read as is; other columns such as Div, FTR or the odds are ignored.
+var csvColumns = map[string]string{
+	"date":      "date",
+	"time":      "time",
+	"datetime":  "dateTime",
+	"hometeam":  "home",
+	"home":      "home",
+	"homecode":  "home",
+	"awayteam":  "away",
+	"away":      "away",
+	"awaycode":  "away",
+	"fthg":      "homeGoals",
+	"hg":        "homeGoals",
+	"homegoals": "homeGoals",
+	"ftag":      "awayGoals",
+	"ag":        "awayGoals",
+	"awaygoals": "awayGoals",
+	"season":    "season",
+	"matchday":  "matchday",
+	"round":     "matchday",
+}
+
+// csvDates are the accepted Date formats, football-data's first.
+var csvDates = []string{"02/01/2006", "02/01/06", "2006-01-02"}
+
+// ParseCSV reads rows with a header line. Date and Time are local to loc;
+// a row without Time kicks off at DefaultKickoff. Rows that cannot be read
+// are returned with their problem so the import reports them by line.
+func ParseCSV(r io.Reader, loc *time.Location) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, errors.New("CSV must start with a header line")
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if f, ok := csvColumns[h]; ok {
+			if _, dup := col[f]; !dup {
+				col[f] = i
+			}
+		}
+	}
+	if _, ok := col["home"]; !ok {
+		return nil, errors.New("CSV needs HomeTeam and AwayTeam columns")
+	}
+	if _, ok := col["away"]; !ok {
+		return nil, errors.New("CSV needs HomeTeam and AwayTeam columns")
+	}
+	_, hasDate := col["date"]
+	_, hasDateTime := col["dateTime"]
+	if !hasDate && !hasDateTime {
+		return nil, errors.New("CSV needs a Date or DateTime column")
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				rows = append(rows, Row{Row: line, problem: perr.Err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		get := func(f string) string {
+			if i, ok := col[f]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if strings.Join(rec, "") == "" {
+			continue
+		}
+
+		row := Row{Row: line, HomeCode: get("home"), AwayCode: get("away"), Season: get("season")}
+		row.problem = readCSVRow(&row, get, loc)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readCSVRow fills the typed fields of row and returns what is wrong with them.
+func readCSVRow(row *Row, get func(string) string, loc *time.Location) string {
+	if v := get("dateTime"); v != "" {
+		row.DateTime = v
+	} else {
+		dt, err := csvDateTime(get("date"), get("time"), loc)
+		if err != nil {
+			return err.Error()
+		}
+		row.DateTime = dt.UTC().Format(time.RFC3339)
+	}
+
+	if v := get("matchday"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "matchday must be a number"
+		}
+		row.Matchday = n
+	}
+
+	hg, ag := get("homeGoals"), get("awayGoals")
+	if hg == "" && ag == "" {
+		return ""
+	}
+	h, herr := strconv.Atoi(hg)
+	a, aerr := strconv.Atoi(ag)
+	if herr != nil || aerr != nil {
+		return "home and away goals must both be numbers"
+	}
+	row.HomeGoals, row.AwayGoals = &h, &a
+	return ""
+}
+
+func csvDateTime(date, clock string, loc *time.Location) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, errors.New("missing date")
+	}
+	if clock == "" {
+		clock = DefaultKickoff
+	}
+	for _, layout := range csvDates {
+		if dt, err := time.ParseInLocation(layout+" 15:04", date+" "+clock, loc); err == nil {
+			return dt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot read date %q time %q (want DD/MM/YYYY and HH:MM)", date, clock)
+}
@@ -0,0 +1,89 @@
+// Package leaderboard ranks players by the goals and assists recorded in
+// match events.
+package leaderboard
+
+import (
+	"sort"
+	"strings"
+
+	"final-by-me/internal/models"
+)
+
+// Entry is one player in a leaderboard.
+type Entry struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"playerId,omitempty"` // "" for free-text (legacy) names
+	Player   string `json:"player"`
+	TeamCode string `json:"teamCode"` // team of the player's latest event
+
+	Goals     int `json:"goals"`     // own goals never count
+	Penalties int `json:"penalties"` // penalty goals, included in Goals unless left out
+	Assists   int `json:"assists"`
+
+	// Matches come from the events, as there are no line-ups: a match counts
+	// when an event names the player, who plays it all unless subbed on, off
+	// or sent off. MinutesPerGoal follows from the same minutes.
+	Matches        int     `json:"matches"`
+	MinutesPerGoal float64 `json:"minutesPerGoal,omitempty"`
+}
+
+// Options tune the counting.
+type Options struct {
+	NoPenalties bool // leave penalty goals out of Goals
+}
+
+// Entries turns the totals of the finished matches (an awarded score was
+// never scored on the pitch) into leaderboard entries.
+func Entries(totals []models.PlayerTotal, opts Options) []Entry {
+	out := make([]Entry, len(totals))
+	for i, t := range totals {
+		e := Entry{PlayerID: t.PlayerID, Player: t.Player, TeamCode: t.TeamCode, Goals: t.Goals, Penalties: t.Penalties, Assists: t.Assists, Matches: t.Matches}
+		if opts.NoPenalties {
+			e.Goals -= t.Penalties
+		}
+		if e.Goals > 0 {
+			e.MinutesPerGoal = float64(t.Minutes) / float64(e.Goals)
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// Scorers ranks the players with goals: most goals first, then most assists
+// and name. Players level on goals share a rank.
+func Scorers(players []Entry) []Entry {
+	return rank(players, func(e Entry) int { return e.Goals }, func(e Entry) int { return e.Assists })
+}
+
+// Assists ranks the players with assists: most assists first, then most
+// goals and name. Players level on assists share a rank.
+func Assists(players []Entry) []Entry {
+	return rank(players, func(e Entry) int { return e.Assists }, func(e Entry) int { return e.Goals })
+}
+
+func rank(players []Entry, primary, secondary func(Entry) int) []Entry {
+	out := []Entry{}
+	for _, e := range players {
+		if primary(e) > 0 {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch {
+		case primary(a) != primary(b):
+			return primary(a) > primary(b)
+		case secondary(a) != secondary(b):
+			return secondary(a) > secondary(b)
+		}
+		return strings.ToLower(a.Player) < strings.ToLower(b.Player)
+	})
+	for i := range out {
+		if i > 0 && primary(out[i]) == primary(out[i-1]) {
+			out[i].Rank = out[i-1].Rank
+		} else {
+			out[i].Rank = i + 1
+		}
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+package live
+
+import (
+	"sync"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+// Update types pushed to subscribers
+const (
+	TypeSnapshot     = "snapshot"
+	TypeEvent        = "event"
+	TypeEventUpdated = "event_updated"
+	TypeEventDeleted = "event_deleted"
+	TypeStatus       = "status"
+	TypePeriod       = "period"
+	TypeShootout     = "shootout"
+	TypeFixture      = "fixture" // kick-off moved, replay scheduled or knockout flag changed
+	TypeFinished     = "finished"
+)
+
+// Update is one change of a match. Match holds the state after the change.
+type Update struct {
+	ID       int64              `json:"id"`
+	Type     string             `json:"type"`
+	MatchKey string             `json:"matchKey"`
+	League   string             `json:"league,omitempty"` // of the home team when published
+	Event    *models.MatchEvent `json:"event,omitempty"`
+	Status   models.MatchStatus `json:"status,omitempty"`
+	Match    *models.Match      `json:"match,omitempty"`
+	At       time.Time          `json:"at"`
+}
+
+// Subscription receives updates accepted by its filter.
+// C is closed when the subscription is closed or evicted as a slow consumer.
+type Subscription struct {
+	C <-chan Update
+
+	ch      chan Update
+	filter  func(Update) bool
+	hub     *Hub
+	evicted bool
+}
+
+// historyAge is how long the history of a match is kept after its last update.
+const historyAge = 6 * time.Hour
+
+// Hub fans out match updates to subscribers and keeps a short history per match
+// so that reconnecting clients can resume.
+type Hub struct {
+	mu        sync.Mutex
+	seq       int64
+	subs      map[*Subscription]struct{}
+	history   map[string][]Update
+	dropped   map[string]int64 // last ID trimmed from history, per match
+	keep      int
+	lastPrune time.Time
+}
+
+func NewHub(keep int) *Hub {
+	if keep <= 0 {
+		keep = 100
+	}
+	return &Hub{
+		subs:    make(map[*Subscription]struct{}),
+		history: make(map[string][]Update),
+		dropped: make(map[string]int64),
+		keep:    keep,
+	}
+}
+
+// Publish assigns the next ID to u and delivers it without blocking.
+// Subscribers whose buffer is full are evicted.
+func (h *Hub) Publish(u Update) Update {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	u.ID = h.seq
+	if u.At.IsZero() {
+		u.At = time.Now()
+	}
+
+	hist := append(h.history[u.MatchKey], u)
+	if len(hist) > h.keep {
+		h.dropped[u.MatchKey] = hist[len(hist)-h.keep-1].ID
+		hist = hist[len(hist)-h.keep:]
+	}
+	h.history[u.MatchKey] = hist
+	h.prune(u.At)
+
+	for s := range h.subs {
+		if s.filter != nil && !s.filter(u) {
+			continue
+		}
+		select {
+		case s.ch <- u:
+		default:
+			s.evicted = true
+			h.remove(s)
+		}
+	}
+	return u
+}
+
+// prune forgets the history of matches without updates for historyAge, at
+// most once a minute. Must be called with h.mu held.
+func (h *Hub) prune(now time.Time) {
+	if now.Sub(h.lastPrune) < time.Minute {
+		return
+	}
+	h.lastPrune = now
+	for key, hist := range h.history {
+		if now.Sub(hist[len(hist)-1].At) > historyAge {
+			delete(h.history, key)
+			delete(h.dropped, key)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber. A nil filter accepts every update.
+func (h *Hub) Subscribe(buffer int, filter func(Update) bool) *Subscription {
+	if buffer <= 0 {
+		buffer = 16
+	}
+	ch := make(chan Update, buffer)
+	s := &Subscription{C: ch, ch: ch, filter: filter, hub: h}
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Since returns the updates of a match published after lastID.
+// ok is false when lastID is older than the kept history.
+func (h *Hub) Since(matchKey string, lastID int64) (out []Update, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// IDs from a previous process or trimmed (or pruned) history cannot be resumed
+	hist, kept := h.history[matchKey]
+	if lastID > h.seq || lastID < h.dropped[matchKey] || (!kept && lastID > 0) {
+		return nil, false
+	}
+	for _, u := range hist {
+		if u.ID > lastID {
+			out = append(out, u)
+		}
+	}
+	return out, true
+}
+
+// SetFilter replaces the filter of a live subscription.
+func (s *Subscription) SetFilter(filter func(Update) bool) {
+	s.hub.mu.Lock()
+	s.filter = filter
+	s.hub.mu.Unlock()
+}
+
+// Evicted reports whether the hub dropped the subscription as a slow consumer.
+func (s *Subscription) Evicted() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.evicted
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	s.hub.remove(s)
+	s.hub.mu.Unlock()
+}
+
+// remove must be called with h.mu held
+func (h *Hub) remove(s *Subscription) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	close(s.ch)
+}
@@ -0,0 +1,250 @@
+package live
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal RFC 6455 server side: text/close/ping/pong frames, no extensions.
+// Binary messages are refused with 1003, unknown opcodes with 1002.
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// Close codes used by the hub
+const (
+	CloseNormal       = 1000
+	CloseGoingAway    = 1001
+	CloseProtocol     = 1002
+	CloseUnsupported  = 1003
+	CloseTooBig       = 1009
+	CloseTryAgainLate = 1013
+)
+
+const wsMaxMessage = 64 << 10
+
+var ErrWSClosed = errors.New("websocket closed")
+
+type WSConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+// Upgrade performs the WebSocket handshake and takes over the connection.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*WSConn, error) {
+	if !headerHas(r.Header, "Connection", "upgrade") || !headerHas(r.Header, "Upgrade", "websocket") {
+		http.Error(w, `{"error":"websocket upgrade required"}`, http.StatusBadRequest)
+		return nil, errors.New("not a websocket request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, `{"error":"unsupported websocket version"}`, http.StatusBadRequest)
+		return nil, errors.New("bad websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, `{"error":"missing Sec-WebSocket-Key"}`, http.StatusBadRequest)
+		return nil, errors.New("missing websocket key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, `{"error":"websocket not supported"}`, http.StatusInternalServerError)
+		return nil, errors.New("hijack not supported")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := rw.WriteString(resp); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &WSConn{conn: conn, br: rw.Reader}, nil
+}
+
+// ReadMessage returns the next text message. Pings are answered and pongs
+// passed to onPong; a close frame ends the read loop with ErrWSClosed.
+func (c *WSConn) ReadMessage(onPong func()) ([]byte, error) {
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case OpPing:
+			if err := c.write(OpPong, payload, 5*time.Second); err != nil {
+				return nil, err
+			}
+			continue
+		case OpPong:
+			if onPong != nil {
+				onPong()
+			}
+			continue
+		case OpClose:
+			_ = c.write(OpClose, payload, time.Second)
+			return nil, ErrWSClosed
+		case OpBinary:
+			c.Close(CloseUnsupported, "binary messages not supported")
+			return nil, ErrWSClosed
+		case OpText:
+			if msg != nil {
+				c.Close(CloseProtocol, "expected continuation frame")
+				return nil, ErrWSClosed
+			}
+			msg = []byte{}
+		case OpContinuation:
+			if msg == nil {
+				c.Close(CloseProtocol, "unexpected continuation frame")
+				return nil, ErrWSClosed
+			}
+		default:
+			c.Close(CloseProtocol, "unknown opcode")
+			return nil, ErrWSClosed
+		}
+
+		msg = append(msg, payload...)
+		if len(msg) > wsMaxMessage {
+			c.Close(CloseTooBig, "message too big")
+			return nil, ErrWSClosed
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+func (c *WSConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [2]byte
+	if _, err = io.ReadFull(c.br, h[:]); err != nil {
+		return
+	}
+	fin = h[0]&0x80 != 0
+	op = h[0] & 0x0F
+	masked := h[1]&0x80 != 0
+	n := uint64(h[1] & 0x7F)
+
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+
+	// clients must mask every frame
+	if !masked {
+		c.Close(CloseProtocol, "unmasked frame")
+		return false, 0, nil, ErrWSClosed
+	}
+	if n > wsMaxMessage {
+		c.Close(CloseTooBig, "frame too big")
+		return false, 0, nil, ErrWSClosed
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+func (c *WSConn) WriteText(b []byte) error {
+	return c.write(OpText, b, 10*time.Second)
+}
+
+func (c *WSConn) Ping() error {
+	return c.write(OpPing, nil, 5*time.Second)
+}
+
+// Close sends a close frame with code and reason, then closes the connection.
+func (c *WSConn) Close(code int, reason string) {
+	b := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(b, uint16(code))
+	copy(b[2:], reason)
+	_ = c.write(OpClose, b, time.Second)
+	_ = c.conn.Close()
+}
+
+func (c *WSConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *WSConn) write(op byte, payload []byte, timeout time.Duration) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	hdr := make([]byte, 0, 10)
+	hdr = append(hdr, 0x80|op)
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 126, byte(n>>8), byte(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := c.conn.Write(append(hdr, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
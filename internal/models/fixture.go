@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// Reschedule records a kick-off change; the matchKey never changes.
+type Reschedule struct {
+	From   time.Time `bson:"from" json:"from"`
+	To     time.Time `bson:"to" json:"to"`
+	At     time.Time `bson:"at" json:"at"`
+	By     string    `bson:"by,omitempty" json:"by,omitempty"`
+	Reason string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// FixtureEdit changes a fixture in one write; nil fields are left as they are.
+type FixtureEdit struct {
+	Matchday   *int
+	Knockout   *bool
+	Reschedule *Reschedule
+}
+
+// Empty reports whether e changes nothing.
+func (e FixtureEdit) Empty() bool {
+	return e.Matchday == nil && e.Knockout == nil && e.Reschedule == nil
+}
+
+// Attempt keeps what was played before an abandoned match was replayed.
+type Attempt struct {
+	KickedOffAt *time.Time     `bson:"kickedOffAt,omitempty" json:"kickedOffAt,omitempty"`
+	AbandonedAt *time.Time     `bson:"abandonedAt,omitempty" json:"abandonedAt,omitempty"`
+	HomeGoals   int            `bson:"homeGoals" json:"homeGoals"`
+	AwayGoals   int            `bson:"awayGoals" json:"awayGoals"`
+	Events      []MatchEvent   `bson:"events" json:"events"`
+	Periods     []PeriodChange `bson:"periods,omitempty" json:"periods,omitempty"`
+}
+
+// Award is a result decided by the league. HomeGoals/AwayGoals of the match stay
+// the played score; the award score is what tables use.
+type Award struct {
+	HomeGoals int       `bson:"homeGoals" json:"homeGoals"`
+	AwayGoals int       `bson:"awayGoals" json:"awayGoals"`
+	Reason    string    `bson:"reason" json:"reason"`
+	At        time.Time `bson:"at" json:"at"`
+	By        string    `bson:"by,omitempty" json:"by,omitempty"`
+}
+
+// Result is the score that counts for standings: the award if there is one.
+func (m Match) Result() (home, away int) {
+	if m.Status == Awarded && m.Award != nil {
+		return m.Award.HomeGoals, m.Award.AwayGoals
+	}
+	return m.HomeGoals, m.AwayGoals
+}
+
+// ResultAt is when the result became final: the award, the final whistle, or
+// the kick-off for results stored without lifecycle timestamps.
+func (m Match) ResultAt() time.Time {
+	switch {
+	case m.Status == Awarded && m.AwardedAt != nil:
+		return *m.AwardedAt
+	case m.FinishedAt != nil:
+		return *m.FinishedAt
+	}
+	return m.DateTime
+}
+
+// Replayed returns m reset for a replay: the abandoned attempt is archived and
+// the score, events, periods and shootout start again.
+func (m Match) Replayed() Match {
+	m.Attempts = append(append([]Attempt{}, m.Attempts...), Attempt{
+		KickedOffAt: m.KickedOffAt,
+		AbandonedAt: m.AbandonedAt,
+		HomeGoals:   m.HomeGoals,
+		AwayGoals:   m.AwayGoals,
+		Events:      m.Events,
+		Periods:     m.Periods,
+	})
+	m.HomeGoals, m.AwayGoals = 0, 0
+	m.Events = []MatchEvent{}
+	m.Period = PeriodNotStarted
+	m.Periods = nil
+	m.Shootout = nil
+	m.KickedOffAt = nil
+	return m
+}
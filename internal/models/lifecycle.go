@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// Lifecycle: scheduled -> live -> finished, plus the off-nominal states.
+const (
+	Postponed MatchStatus = "postponed"
+	Suspended MatchStatus = "suspended"
+	Abandoned MatchStatus = "abandoned"
+	Cancelled MatchStatus = "cancelled"
+	Awarded   MatchStatus = "awarded" // result decided by the league, see Match.Award
+)
+
+// Abandoned -> live resumes the match, abandoned -> scheduled replays it from 0-0.
+var statusNext = map[MatchStatus][]MatchStatus{
+	Scheduled: {Live, Postponed, Cancelled, Awarded},
+	Postponed: {Scheduled, Cancelled, Awarded},
+	Live:      {Finished, Suspended, Abandoned},
+	Suspended: {Live, Abandoned, Awarded},
+	Abandoned: {Live, Scheduled, Awarded},
+	Finished:  {Awarded},
+	Cancelled: {},
+	Awarded:   {},
+}
+
+// StatusChange records one lifecycle transition and who made it.
+type StatusChange struct {
+	From   MatchStatus `bson:"from" json:"from"`
+	To     MatchStatus `bson:"to" json:"to"`
+	At     time.Time   `bson:"at" json:"at"`
+	By     string      `bson:"by,omitempty" json:"by,omitempty"`
+	Reason string      `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+func (s MatchStatus) Valid() bool {
+	_, ok := statusNext[s]
+	return ok
+}
+
+// NextStatuses lists the states a match in s may move to.
+func (s MatchStatus) NextStatuses() []MatchStatus {
+	out := statusNext[s]
+	if out == nil {
+		return []MatchStatus{}
+	}
+	return out
+}
+
+func (s MatchStatus) CanMoveTo(to MatchStatus) bool {
+	for _, n := range statusNext[s] {
+		if n == to {
+			return true
+		}
+	}
+	return false
+}
+
+// HasResult: the match counts in tables (played to the end or awarded).
+func (s MatchStatus) HasResult() bool {
+	return s == Finished || s == Awarded
+}
+
+// Terminal: the match is over. A finished match can still be awarded.
+func (s MatchStatus) Terminal() bool {
+	return s == Finished || s == Cancelled || s == Awarded
+}
+
+// TimestampField is the match field stamped when entering status to
+// ("" if none). kickedOffAt keeps the first kick-off.
+func TimestampField(to MatchStatus) string {
+	switch to {
+	case Live:
+		return "kickedOffAt"
+	case Finished:
+		return "finishedAt"
+	case Postponed:
+		return "postponedAt"
+	case Suspended:
+		return "suspendedAt"
+	case Abandoned:
+		return "abandonedAt"
+	case Cancelled:
+		return "cancelledAt"
+	case Awarded:
+		return "awardedAt"
+	}
+	return ""
+}
+
+// Stamp sets the transition timestamp on m the same way the repository does.
+// Kick-off also starts the first half.
+func (m *Match) Stamp(c StatusChange) {
+	at := c.At
+	switch c.To {
+	case Live:
+		if m.KickedOffAt == nil {
+			m.KickedOffAt = &at
+		}
+		if m.Period == PeriodNotStarted {
+			m.Period = PeriodFirstHalf
+			m.Periods = append(m.Periods, KickOff(c))
+		}
+	case Finished:
+		m.FinishedAt = &at
+	case Postponed:
+		m.PostponedAt = &at
+	case Suspended:
+		m.SuspendedAt = &at
+	case Abandoned:
+		m.AbandonedAt = &at
+	case Cancelled:
+		m.CancelledAt = &at
+	case Awarded:
+		m.AwardedAt = &at
+	}
+	m.Status = c.To
+	m.Transitions = append(m.Transitions, c)
+}
+
+// KickOff is the period change recorded when c makes a match live for the
+// first time.
+func KickOff(c StatusChange) PeriodChange {
+	return PeriodChange{From: PeriodNotStarted, To: PeriodFirstHalf, At: c.At, By: c.By}
+}
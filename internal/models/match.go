@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,24 +16,62 @@ const (
 	Finished  MatchStatus = "finished"
 )
 
+// Event types
+const (
+	EventGoal          = "goal"
+	EventCard          = "card"
+	EventInjury        = "injury"
+	EventVAR           = "var"
+	EventSub           = "sub"
+	EventPenaltyMissed = "penalty_missed"
+)
+
+// Goal subtypes (MatchEvent.GoalType)
+const (
+	GoalOpenPlay = "open_play"
+	GoalPenalty  = "penalty"
+	GoalFreeKick = "free_kick"
+	GoalHeader   = "header"
+	GoalOwnGoal  = "own_goal"
+)
+
 type MatchEvent struct {
-	Type     string `bson:"type" json:"type"` // goal | card | injury | var | sub
-	TeamCode string `bson:"teamCode" json:"teamCode"`
+	ID        string    `bson:"id,omitempty" json:"id,omitempty"` // server-generated; empty on legacy events
+	CreatedAt time.Time `bson:"createdAt,omitempty" json:"createdAt,omitzero"`
+	CreatedBy string    `bson:"createdBy,omitempty" json:"createdBy,omitempty"` // user id of the admin
+
+	Type     string `bson:"type" json:"type"`         // goal | card | injury | var | sub | penalty_missed
+	TeamCode string `bson:"teamCode" json:"teamCode"` // team of the player (for own goals too)
 	Minute   int    `bson:"minute" json:"minute"`
+	Stoppage int    `bson:"stoppage,omitempty" json:"stoppage,omitempty"` // 90+4 -> minute 90, stoppage 4
+
+	Period MatchPeriod `bson:"period,omitempty" json:"period,omitempty"` // period the event was recorded in
 
 	// Common optional fields:
-	Player    string `bson:"player,omitempty" json:"player,omitempty"`
+	Player    string `bson:"player,omitempty" json:"player,omitempty"`       // name; free text on legacy events
+	PlayerID  string `bson:"playerId,omitempty" json:"playerId,omitempty"`   // registered player (see Player)
 	Detail    string `bson:"detail,omitempty" json:"detail,omitempty"`       // e.g. "VAR check: offside"
 	CardColor string `bson:"cardColor,omitempty" json:"cardColor,omitempty"` // yellow | red
 
+	// Goal:
+	GoalType string `bson:"goalType,omitempty" json:"goalType,omitempty"` // open_play | penalty | free_kick | header | own_goal
+	Assist   string `bson:"assist,omitempty" json:"assist,omitempty"`     // optional, never on own goals
+	AssistID string `bson:"assistId,omitempty" json:"assistId,omitempty"`
+
 	// Substitution:
-	PlayerOut string `bson:"playerOut,omitempty" json:"playerOut,omitempty"`
-	PlayerIn  string `bson:"playerIn,omitempty" json:"playerIn,omitempty"`
+	PlayerOut   string `bson:"playerOut,omitempty" json:"playerOut,omitempty"`
+	PlayerIn    string `bson:"playerIn,omitempty" json:"playerIn,omitempty"`
+	PlayerOutID string `bson:"playerOutId,omitempty" json:"playerOutId,omitempty"`
+	PlayerInID  string `bson:"playerInId,omitempty" json:"playerInId,omitempty"`
 }
 
 type Match struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	MatchKey string             `bson:"matchKey" json:"matchKey"`
+	// Season is the Season.ID the match belongs to; "" for matches created before seasons.
+	Season string `bson:"season,omitempty" json:"season,omitempty"`
+	// Matchday is the league round (1-based); 0 when not set, see standings.Matchdays.
+	Matchday int `bson:"matchday,omitempty" json:"matchday,omitempty"`
 
 	DateTime  time.Time   `bson:"dateTime" json:"dateTime"`
 	HomeCode  string      `bson:"homeCode" json:"homeCode"`
@@ -39,6 +79,207 @@ type Match struct {
 	HomeGoals int         `bson:"homeGoals" json:"homeGoals"`
 	AwayGoals int         `bson:"awayGoals" json:"awayGoals"`
 	Status    MatchStatus `bson:"status" json:"status"`
+	// ScoreOnly marks an imported result without goal events: the events
+	// do not back the score, so score reconciliation leaves it alone.
+	ScoreOnly bool `bson:"scoreOnly,omitempty" json:"scoreOnly,omitempty"`
+
+	// Lifecycle history and timestamps (see lifecycle.go)
+	Transitions []StatusChange `bson:"transitions,omitempty" json:"transitions,omitempty"`
+	KickedOffAt *time.Time     `bson:"kickedOffAt,omitempty" json:"kickedOffAt,omitempty"`
+	FinishedAt  *time.Time     `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	PostponedAt *time.Time     `bson:"postponedAt,omitempty" json:"postponedAt,omitempty"`
+	SuspendedAt *time.Time     `bson:"suspendedAt,omitempty" json:"suspendedAt,omitempty"`
+	AbandonedAt *time.Time     `bson:"abandonedAt,omitempty" json:"abandonedAt,omitempty"`
+	CancelledAt *time.Time     `bson:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
+	AwardedAt   *time.Time     `bson:"awardedAt,omitempty" json:"awardedAt,omitempty"`
+
+	// Fixture changes (see fixture.go)
+	Reschedules []Reschedule `bson:"reschedules,omitempty" json:"reschedules,omitempty"`
+	Attempts    []Attempt    `bson:"attempts,omitempty" json:"attempts,omitempty"`
+	Award       *Award       `bson:"award,omitempty" json:"award,omitempty"`
+
+	// Knockout matches cannot end level: a shootout decides them.
+	Knockout bool      `bson:"knockout,omitempty" json:"knockout,omitempty"`
+	Shootout *Shootout `bson:"shootout,omitempty" json:"shootout,omitempty"`
+
+	Period  MatchPeriod    `bson:"period,omitempty" json:"period,omitempty"`
+	Periods []PeriodChange `bson:"periods,omitempty" json:"periods,omitempty"`
 
 	Events []MatchEvent `bson:"events" json:"events"`
+	// Corrections is the audit trail of event changes after the final whistle.
+	Corrections []EventCorrection `bson:"corrections,omitempty" json:"corrections,omitempty"`
+}
+
+// Event correction actions
+const (
+	CorrectionUpdated = "updated"
+	CorrectionDeleted = "deleted"
+)
+
+// EventCorrection records a change to an event of a finished match.
+type EventCorrection struct {
+	EventID string      `bson:"eventId" json:"eventId"`
+	Action  string      `bson:"action" json:"action"` // updated | deleted
+	Before  MatchEvent  `bson:"before" json:"before"`
+	After   *MatchEvent `bson:"after,omitempty" json:"after,omitempty"`
+	Reason  string      `bson:"reason" json:"reason"`
+	At      time.Time   `bson:"at" json:"at"`
+	By      string      `bson:"by,omitempty" json:"by,omitempty"`
+}
+
+// Normalize trims the fields of e and validates them by type.
+// Returns the error message for a 400, or "" if the event is valid.
+func (e *MatchEvent) Normalize() string {
+	e.Type = strings.ToLower(strings.TrimSpace(e.Type))
+	e.TeamCode = strings.ToUpper(strings.TrimSpace(e.TeamCode))
+	e.Player = strings.TrimSpace(e.Player)
+	e.Detail = strings.TrimSpace(e.Detail)
+	e.CardColor = strings.ToLower(strings.TrimSpace(e.CardColor))
+	e.GoalType = strings.ToLower(strings.TrimSpace(e.GoalType))
+	e.PlayerOut = strings.TrimSpace(e.PlayerOut)
+	e.PlayerIn = strings.TrimSpace(e.PlayerIn)
+	e.PlayerID = strings.TrimSpace(e.PlayerID)
+	e.PlayerOutID = strings.TrimSpace(e.PlayerOutID)
+	e.PlayerInID = strings.TrimSpace(e.PlayerInID)
+	e.Assist = strings.TrimSpace(e.Assist)
+	e.AssistID = strings.TrimSpace(e.AssistID)
+	hasPlayer := e.Player != "" || e.PlayerID != ""
+
+	if e.Type == "" || e.TeamCode == "" || e.Minute <= 0 || e.Minute > 130 {
+		return "type, teamCode, minute required (minute 1..130)"
+	}
+	if e.Stoppage < 0 || e.Stoppage > MaxStoppage {
+		return "stoppage must be 0..30"
+	}
+
+	allowed := map[string]bool{
+		EventGoal: true, EventCard: true, EventInjury: true,
+		EventVAR: true, EventSub: true, EventPenaltyMissed: true,
+	}
+	if !allowed[e.Type] {
+		return "type must be goal|card|injury|var|sub|penalty_missed"
+	}
+
+	// validation by type
+	if e.Type == EventGoal {
+		if !hasPlayer {
+			return "goal requires player or playerId"
+		}
+		if e.GoalType == "" {
+			e.GoalType = GoalOpenPlay
+		}
+		goalTypes := map[string]bool{
+			GoalOpenPlay: true, GoalPenalty: true, GoalFreeKick: true,
+			GoalHeader: true, GoalOwnGoal: true,
+		}
+		if !goalTypes[e.GoalType] {
+			return "goalType must be open_play|penalty|free_kick|header|own_goal"
+		}
+		if e.Assist != "" || e.AssistID != "" {
+			if e.GoalType == GoalOwnGoal {
+				return "own goals have no assist"
+			}
+			if (e.AssistID != "" && e.AssistID == e.PlayerID) || (e.AssistID == "" && e.PlayerID == "" && strings.EqualFold(e.Assist, e.Player)) {
+				return "a player cannot assist their own goal"
+			}
+		}
+	} else if e.GoalType != "" {
+		return "goalType is only allowed on goals"
+	} else if e.Assist != "" || e.AssistID != "" {
+		return "assist is only allowed on goals"
+	}
+	if e.Type == EventPenaltyMissed && !hasPlayer {
+		return "penalty_missed requires player or playerId"
+	}
+	if e.Type == EventCard && (!hasPlayer || (e.CardColor != "yellow" && e.CardColor != "red")) {
+		return "card requires player (or playerId) and cardColor yellow|red"
+	}
+	if e.Type == EventSub && ((e.PlayerOut == "" && e.PlayerOutID == "") || (e.PlayerIn == "" && e.PlayerInID == "")) {
+		return "sub requires playerOut and playerIn (or their ids)"
+	}
+	return ""
+}
+
+// Clock renders the event time, e.g. 90+4'.
+func (e MatchEvent) Clock() string {
+	return FormatClock(e.Minute, e.Stoppage)
+}
+
+// Before orders events by match time (45+2 comes before 46).
+func (e MatchEvent) Before(o MatchEvent) bool {
+	if e.Minute != o.Minute {
+		return e.Minute < o.Minute
+	}
+	return e.Stoppage < o.Stoppage
+}
+
+// MarshalJSON adds the rendered "time" field for listings and the frontend.
+func (e MatchEvent) MarshalJSON() ([]byte, error) {
+	type plain MatchEvent
+	return json.Marshal(struct {
+		plain
+		Time string `json:"time"`
+	}{plain(e), e.Clock()})
+}
+
+// ScoringTeam is the team credited with goal e: the opponent for an own goal.
+// Returns "" if e is not a goal of a team in this match.
+func (m Match) ScoringTeam(e MatchEvent) string {
+	if e.Type != EventGoal {
+		return ""
+	}
+	if e.GoalType != GoalOwnGoal {
+		if e.TeamCode == m.HomeCode || e.TeamCode == m.AwayCode {
+			return e.TeamCode
+		}
+		return ""
+	}
+	switch e.TeamCode {
+	case m.HomeCode:
+		return m.AwayCode
+	case m.AwayCode:
+		return m.HomeCode
+	}
+	return ""
+}
+
+// GoalDelta returns how much e adds to the home and away score of m.
+func (m Match) GoalDelta(e MatchEvent) (home, away int) {
+	switch m.ScoringTeam(e) {
+	case "":
+		return 0, 0
+	case m.HomeCode:
+		return 1, 0
+	default:
+		return 0, 1
+	}
+}
+
+// ScoreFromEvents is the score defined by the goal events.
+// HomeGoals/AwayGoals are a stored copy of it.
+func (m Match) ScoreFromEvents() (home, away int) {
+	for _, e := range m.Events {
+		h, a := m.GoalDelta(e)
+		home += h
+		away += a
+	}
+	return home, away
+}
+
+// Rescore sets the score from the events, except on a ScoreOnly match whose
+// imported score the events do not back. MatchRepo.scoreStage is its Mongo twin.
+func (m *Match) Rescore() {
+	if !m.ScoreOnly {
+		m.HomeGoals, m.AwayGoals = m.ScoreFromEvents()
+	}
+}
+
+// FindEvent returns the event with the given id.
+func (m Match) FindEvent(id string) (MatchEvent, bool) {
+	for _, e := range m.Events {
+		if e.ID != "" && e.ID == id {
+			return e, true
+		}
+	}
+	return MatchEvent{}, false
 }
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type MatchPeriod string
+
+const (
+	PeriodNotStarted MatchPeriod = ""
+	PeriodFirstHalf  MatchPeriod = "first_half"
+	PeriodHalfTime   MatchPeriod = "half_time"
+	PeriodSecondHalf MatchPeriod = "second_half"
+	PeriodExtraTime1 MatchPeriod = "extra_time_1"
+	PeriodExtraTime2 MatchPeriod = "extra_time_2"
+	PeriodPenalties  MatchPeriod = "penalties"
+)
+
+// PeriodChange records one explicit period transition.
+type PeriodChange struct {
+	From MatchPeriod `bson:"from" json:"from"`
+	To   MatchPeriod `bson:"to" json:"to"`
+	At   time.Time   `bson:"at" json:"at"`
+	By   string      `bson:"by,omitempty" json:"by,omitempty"`
+}
+
+// periodNext: allowed transitions (ET and penalties are optional)
+var periodNext = map[MatchPeriod][]MatchPeriod{
+	PeriodNotStarted: {PeriodFirstHalf},
+	PeriodFirstHalf:  {PeriodHalfTime},
+	PeriodHalfTime:   {PeriodSecondHalf},
+	PeriodSecondHalf: {PeriodExtraTime1, PeriodPenalties},
+	PeriodExtraTime1: {PeriodExtraTime2},
+	PeriodExtraTime2: {PeriodPenalties},
+}
+
+// periodClock: regular minutes of each playing period; stoppage is only
+// allowed on the last minute (45+2, 90+4, ...)
+var periodClock = map[MatchPeriod][2]int{
+	PeriodFirstHalf:  {1, 45},
+	PeriodSecondHalf: {46, 90},
+	PeriodExtraTime1: {91, 105},
+	PeriodExtraTime2: {106, 120},
+}
+
+const MaxStoppage = 30
+
+func (p MatchPeriod) Valid() bool {
+	if p == PeriodNotStarted {
+		return false
+	}
+	_, ok := periodNext[p]
+	return ok || p == PeriodPenalties
+}
+
+// NextPeriods lists the periods a match in p may move to.
+func (p MatchPeriod) NextPeriods() []MatchPeriod {
+	return periodNext[p]
+}
+
+func (p MatchPeriod) CanMoveTo(to MatchPeriod) bool {
+	for _, n := range periodNext[p] {
+		if n == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEventTime validates minute+stoppage of an event recorded during period p.
+// Breaks accept the boundary minutes around them (e.g. a half-time sub is 45+ or 46).
+// Matches without period tracking (legacy) only get the old 1..130 rule.
+func CheckEventTime(p MatchPeriod, minute, stoppage int) error {
+	if stoppage < 0 || stoppage > MaxStoppage {
+		return fmt.Errorf("stoppage must be 0..%d", MaxStoppage)
+	}
+
+	switch p {
+	case PeriodNotStarted:
+		if minute < 1 || minute > 130 {
+			return fmt.Errorf("minute must be 1..130")
+		}
+		if stoppage > 0 && minute != 45 && minute != 90 && minute != 105 && minute != 120 {
+			return fmt.Errorf("stoppage is only allowed at 45, 90, 105 or 120")
+		}
+		return nil
+	case PeriodHalfTime:
+		if (minute == 45) || (minute == 46 && stoppage == 0) {
+			return nil
+		}
+		return fmt.Errorf("during half_time minute must be 45+ or 46")
+	case PeriodPenalties:
+		if minute == 120 || minute == 90 {
+			return nil
+		}
+		return fmt.Errorf("during penalties minute must be 90+ or 120+")
+	}
+
+	clock, ok := periodClock[p]
+	if !ok {
+		return fmt.Errorf("unknown period %q", p)
+	}
+	if minute < clock[0] || minute > clock[1] {
+		return fmt.Errorf("during %s minute must be %d..%d", p, clock[0], clock[1])
+	}
+	if stoppage > 0 && minute != clock[1] {
+		return fmt.Errorf("stoppage is only allowed at minute %d during %s", clock[1], p)
+	}
+	return nil
+}
+
+// FormatClock renders a match time like 37' or 90+4'.
+func FormatClock(minute, stoppage int) string {
+	if stoppage > 0 {
+		return fmt.Sprintf("%d+%d'", minute, stoppage)
+	}
+	return fmt.Sprintf("%d'", minute)
+}
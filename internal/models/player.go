@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Player positions
+const (
+	PositionGoalkeeper = "GK"
+	PositionDefender   = "DF"
+	PositionMidfielder = "MF"
+	PositionForward    = "FW"
+)
+
+// Player is a registered player. Match events reference players by ID, so a
+// player is never deleted: leaving a squad only clears TeamCode.
+type Player struct {
+	ID       string `bson:"_id" json:"id"`
+	Name     string `bson:"name" json:"name"`
+	Number   int    `bson:"number,omitempty" json:"number,omitempty"` // shirt number in the current team, 1..99
+	Position string `bson:"position,omitempty" json:"position,omitempty"`
+	TeamCode string `bson:"teamCode,omitempty" json:"teamCode,omitempty"` // current team; "" without a club
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+func ValidPosition(p string) bool {
+	switch p {
+	case PositionGoalkeeper, PositionDefender, PositionMidfielder, PositionForward:
+		return true
+	}
+	return false
+}
+
+// PlayerKey identifies the player an event names: the registered id, or for
+// free-text (legacy) names the name within the team.
+func PlayerKey(id, name, team string) string {
+	if id != "" {
+		return id
+	}
+	return team + ":" + strings.ToLower(name)
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MatchSort is the order of a match listing. Ties are broken by the match
+// id, so every order is total and can be paginated with a cursor.
+type MatchSort string
+
+const (
+	SortDate         MatchSort = "dateTime"  // kick-off, earliest first
+	SortDateDesc     MatchSort = "-dateTime" // latest first
+	SortMatchday     MatchSort = "matchday"  // round, then kick-off
+	SortMatchdayDesc MatchSort = "-matchday"
+)
+
+func (s MatchSort) Valid() bool {
+	switch s {
+	case SortDate, SortDateDesc, SortMatchday, SortMatchdayDesc:
+		return true
+	}
+	return false
+}
+
+// Desc reports whether s is descending.
+func (s MatchSort) Desc() bool {
+	return s == SortDateDesc || s == SortMatchdayDesc
+}
+
+// ByMatchday reports whether s orders by round first.
+func (s MatchSort) ByMatchday() bool {
+	return s == SortMatchday || s == SortMatchdayDesc
+}
+
+// MatchQuery filters, orders and pages a match listing. Zero fields do not filter.
+type MatchQuery struct {
+	// Seasons limits the listing to these seasons; "" stands for matches
+	// without a season. nil: every season.
+	Seasons []string
+	// NoSeasonHome limits the matches without a season to these home teams
+	// (a league's teams); nil: no limit.
+	NoSeasonHome []string
+
+	Team     string // home or away
+	Opponent string // with Team: only the meetings of the two
+	Statuses []MatchStatus
+	From     time.Time // kick-off at or after
+	To       time.Time // kick-off before
+	Matchday int
+
+	Sort   MatchSort
+	After  *MatchCursor // continue after this match
+	Limit  int
+	Events bool // load the events; listings leave them out by default
+}
+
+// MatchCursor is the position of a match in a sorted listing.
+type MatchCursor struct {
+	Sort     MatchSort          `json:"s"`
+	Matchday int                `json:"md,omitempty"`
+	DateTime time.Time          `json:"dt"`
+	ID       primitive.ObjectID `json:"id"`
+}
+
+// CursorOf is the position of m in a listing sorted by s.
+func CursorOf(m Match, s MatchSort) MatchCursor {
+	c := MatchCursor{Sort: s, DateTime: m.DateTime, ID: m.ID}
+	if s.ByMatchday() {
+		c.Matchday = m.Matchday
+	}
+	return c
+}
+
+// Before reports whether m comes before the cursor position in its order
+// (or is the match at it). Ids compare bytewise, as in MongoDB.
+func (c MatchCursor) Before(m Match) bool {
+	var d int
+	switch {
+	case c.Sort.ByMatchday() && m.Matchday != c.Matchday:
+		d = cmp.Compare(m.Matchday, c.Matchday)
+	case !m.DateTime.Equal(c.DateTime):
+		d = m.DateTime.Compare(c.DateTime)
+	default:
+		d = bytes.Compare(m.ID[:], c.ID[:])
+	}
+	if c.Sort.Desc() {
+		d = -d
+	}
+	return d <= 0
+}
+
+// Encode returns the opaque token handed to clients.
+func (c MatchCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reads a token made by Encode.
+func DecodeCursor(token string) (MatchCursor, error) {
+	var c MatchCursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(b, &c) != nil || !c.Sort.Valid() || c.ID.IsZero() {
+		return MatchCursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TieBreaker orders teams level on points. They are applied in the order the
+// league lists them; head-to-head criteria only look at matches between the
+// teams still tied at that step.
+type TieBreaker string
+
+const (
+	TieGoalDifference   TieBreaker = "goal_difference"
+	TieGoalsFor         TieBreaker = "goals_for"
+	TieHeadToHeadPoints TieBreaker = "h2h_points"
+	TieHeadToHeadGD     TieBreaker = "h2h_goal_difference"
+	TieAwayGoals        TieBreaker = "away_goals"
+	TieWins             TieBreaker = "wins"
+	TieFairPlay         TieBreaker = "fair_play" // fewer card points ranks higher
+)
+
+var tieBreakers = map[TieBreaker]bool{
+	TieGoalDifference:   true,
+	TieGoalsFor:         true,
+	TieHeadToHeadPoints: true,
+	TieHeadToHeadGD:     true,
+	TieAwayGoals:        true,
+	TieWins:             true,
+	TieFairPlay:         true,
+}
+
+// Bonus point schemes
+const (
+	BonusGoalsScored = "goals_scored" // +Points when a team scores at least Threshold goals
+	BonusCleanSheet  = "clean_sheet"  // +Points for not conceding
+	BonusNarrowLoss  = "narrow_loss"  // +Points for losing by at most Threshold goals
+	BonusShootoutWin = "shootout_win" // +Points for winning the shootout of a level match
+)
+
+// Fair play card points
+const (
+	FairPlayYellow = 1
+	FairPlayRed    = 3
+)
+
+type Bonus struct {
+	Kind      string `bson:"kind" json:"kind"`
+	Threshold int    `bson:"threshold,omitempty" json:"threshold,omitempty"`
+	Points    int    `bson:"points" json:"points"`
+}
+
+// LeagueRules is how a league's table is scored and ordered.
+type LeagueRules struct {
+	League      string       `bson:"_id" json:"league"`
+	PointsWin   int          `bson:"pointsWin" json:"pointsWin"`
+	PointsDraw  int          `bson:"pointsDraw" json:"pointsDraw"`
+	PointsLoss  int          `bson:"pointsLoss" json:"pointsLoss"`
+	TieBreakers []TieBreaker `bson:"tieBreakers" json:"tieBreakers"`
+	Bonuses     []Bonus      `bson:"bonuses,omitempty" json:"bonuses,omitempty"`
+	// Discipline turns cards into suspensions; nil: DefaultDiscipline.
+	Discipline *DisciplineRules `bson:"discipline,omitempty" json:"discipline,omitempty"`
+	UpdatedAt  time.Time        `bson:"updatedAt,omitempty" json:"updatedAt,omitzero"`
+	UpdatedBy  string           `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+}
+
+// DisciplineRules are the bans a league hands out for cards, in matches of
+// the player's team in the same competition.
+type DisciplineRules struct {
+	// YellowBans are the bans for reaching a number of yellow cards, by
+	// ascending Yellows. The two yellows of a sending-off do not count.
+	YellowBans      []YellowBan `bson:"yellowBans" json:"yellowBans"`
+	RedBan          int         `bson:"redBan" json:"redBan"`                   // straight red card
+	SecondYellowBan int         `bson:"secondYellowBan" json:"secondYellowBan"` // sent off for a second yellow
+}
+
+type YellowBan struct {
+	Yellows int `bson:"yellows" json:"yellows"`
+	Matches int `bson:"matches" json:"matches"`
+}
+
+// DefaultDiscipline: one match at 5 yellows, two at 10, three at 15; three
+// for a straight red, one for a second yellow.
+func DefaultDiscipline() DisciplineRules {
+	return DisciplineRules{
+		YellowBans:      []YellowBan{{Yellows: 5, Matches: 1}, {Yellows: 10, Matches: 2}, {Yellows: 15, Matches: 3}},
+		RedBan:          3,
+		SecondYellowBan: 1,
+	}
+}
+
+// DisciplineRules returns the discipline of r, or the defaults.
+func (r LeagueRules) DisciplineRules() DisciplineRules {
+	if r.Discipline == nil {
+		return DefaultDiscipline()
+	}
+	return *r.Discipline
+}
+
+// DefaultRules: 3/1/0, then goal difference and goals scored.
+func DefaultRules(league string) LeagueRules {
+	return LeagueRules{
+		League:      league,
+		PointsWin:   3,
+		PointsDraw:  1,
+		PointsLoss:  0,
+		TieBreakers: []TieBreaker{TieGoalDifference, TieGoalsFor},
+	}
+}
+
+// Validate reports the first problem with r, or nil.
+func (r LeagueRules) Validate() error {
+	if r.PointsWin < 0 || r.PointsDraw < 0 || r.PointsLoss < 0 {
+		return fmt.Errorf("points must be >= 0")
+	}
+	if r.PointsWin < r.PointsDraw || r.PointsDraw < r.PointsLoss {
+		return fmt.Errorf("points must satisfy win >= draw >= loss")
+	}
+	seen := map[TieBreaker]bool{}
+	for _, t := range r.TieBreakers {
+		if !tieBreakers[t] {
+			return fmt.Errorf("unknown tie-breaker %q", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("tie-breaker %q listed twice", t)
+		}
+		seen[t] = true
+	}
+	for _, b := range r.Bonuses {
+		switch b.Kind {
+		case BonusGoalsScored, BonusNarrowLoss:
+			if b.Threshold < 1 {
+				return fmt.Errorf("%s bonus needs threshold >= 1", b.Kind)
+			}
+		case BonusCleanSheet, BonusShootoutWin:
+		default:
+			return fmt.Errorf("unknown bonus %q", b.Kind)
+		}
+		if b.Points < 1 {
+			return fmt.Errorf("%s bonus needs points >= 1", b.Kind)
+		}
+	}
+	if d := r.Discipline; d != nil {
+		if d.RedBan < 0 || d.SecondYellowBan < 0 {
+			return fmt.Errorf("discipline bans must be >= 0 matches")
+		}
+		prev := 0
+		for _, b := range d.YellowBans {
+			if b.Yellows <= prev {
+				return fmt.Errorf("discipline yellowBans must have ascending yellows >= 1")
+			}
+			if b.Matches < 1 {
+				return fmt.Errorf("discipline yellow ban at %d needs matches >= 1", b.Yellows)
+			}
+			prev = b.Yellows
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type SeasonStatus string
+
+const (
+	SeasonOpen   SeasonStatus = "open"
+	SeasonClosed SeasonStatus = "closed"
+)
+
+// Season is one league campaign, e.g. EPL 2025-26 (ID "EPL-2025-26").
+type Season struct {
+	ID        string       `bson:"_id" json:"id"`
+	League    string       `bson:"league" json:"league"`
+	Name      string       `bson:"name" json:"name"` // 2025-26
+	StartDate time.Time    `bson:"startDate" json:"startDate"`
+	EndDate   time.Time    `bson:"endDate" json:"endDate"`
+	Teams     []string     `bson:"teams" json:"teams"` // participating team codes
+	Status    SeasonStatus `bson:"status" json:"status"`
+
+	// Set on close: the table as it stood at the end of the season.
+	ClosedAt   *time.Time `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+	ClosedBy   string     `bson:"closedBy,omitempty" json:"closedBy,omitempty"`
+	FinalTable []TableRow `bson:"finalTable,omitempty" json:"finalTable,omitempty"`
+}
+
+func SeasonID(league, name string) string {
+	return league + "-" + name
+}
+
+// Contains reports whether t falls within the season dates (end day inclusive).
+func (s Season) Contains(t time.Time) bool {
+	return !t.Before(s.StartDate) && t.Before(s.EndDate.AddDate(0, 0, 1))
+}
+
+func (s Season) HasTeam(code string) bool {
+	for _, c := range s.Teams {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether the two seasons share any day.
+func (s Season) Overlaps(o Season) bool {
+	return !s.StartDate.After(o.EndDate) && !o.StartDate.After(s.EndDate)
+}
+
+// CurrentSeason picks the season of one league in play at now: the one whose
+// dates contain now, else the latest one that has started, else the earliest
+// upcoming one. ok is false when the league has no seasons.
+func CurrentSeason(list []Season, now time.Time) (Season, bool) {
+	var started, upcoming *Season
+	for i := range list {
+		s := &list[i]
+		if s.Contains(now) {
+			return *s, true
+		}
+		if !s.StartDate.After(now) {
+			if started == nil || s.StartDate.After(started.StartDate) {
+				started = s
+			}
+		} else if upcoming == nil || s.StartDate.Before(upcoming.StartDate) {
+			upcoming = s
+		}
+	}
+	switch {
+	case started != nil:
+		return *started, true
+	case upcoming != nil:
+		return *upcoming, true
+	}
+	return Season{}, false
+}
@@ -0,0 +1,119 @@
+package models
+
+import "time"
+
+// Shootout kick outcomes
+const (
+	KickScored = "scored"
+	KickMissed = "missed"
+	KickSaved  = "saved"
+)
+
+// shootoutRounds is the number of kicks per team before sudden death
+const shootoutRounds = 5
+
+type ShootoutKick struct {
+	ID        string    `bson:"id" json:"id"`
+	Order     int       `bson:"order" json:"order"` // 1-based, across both teams
+	TeamCode  string    `bson:"teamCode" json:"teamCode"`
+	Taker     string    `bson:"taker" json:"taker"`
+	Outcome   string    `bson:"outcome" json:"outcome"` // scored | missed | saved
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	CreatedBy string    `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+}
+
+// Shootout is kept apart from the match score; HomeScore/AwayScore count scored kicks.
+type Shootout struct {
+	FirstTeam string         `bson:"firstTeam" json:"firstTeam"`
+	Kicks     []ShootoutKick `bson:"kicks" json:"kicks"`
+	HomeScore int            `bson:"homeScore" json:"homeScore"`
+	AwayScore int            `bson:"awayScore" json:"awayScore"`
+	Winner    string         `bson:"winner,omitempty" json:"winner,omitempty"`
+	Completed bool           `bson:"completed" json:"completed"`
+}
+
+// NextKicker is the team due to take the next kick ("" before the first kick,
+// when either team may start).
+func (s Shootout) NextKicker(home, away string) string {
+	if s.FirstTeam == "" || len(s.Kicks) == 0 {
+		return ""
+	}
+	second := home
+	if s.FirstTeam == home {
+		second = away
+	}
+	if len(s.Kicks)%2 == 0 {
+		return s.FirstTeam
+	}
+	return second
+}
+
+// WithKick returns the shootout after k, with scores and result recomputed.
+func (s Shootout) WithKick(home, away string, k ShootoutKick) Shootout {
+	out := Shootout{FirstTeam: s.FirstTeam, Kicks: append(append([]ShootoutKick{}, s.Kicks...), k)}
+	if out.FirstTeam == "" {
+		out.FirstTeam = k.TeamCode
+	}
+	out.settle(home, away)
+	return out
+}
+
+// settle recomputes scores and applies the standard rules: best of five kicks
+// each (decided early once one side cannot catch up), then sudden death
+// after every pair of kicks.
+func (s *Shootout) settle(home, away string) {
+	var takenH, takenA int
+	s.HomeScore, s.AwayScore = 0, 0
+	for _, k := range s.Kicks {
+		scored := k.Outcome == KickScored
+		switch k.TeamCode {
+		case home:
+			takenH++
+			if scored {
+				s.HomeScore++
+			}
+		case away:
+			takenA++
+			if scored {
+				s.AwayScore++
+			}
+		}
+	}
+
+	s.Winner, s.Completed = "", false
+	if takenH <= shootoutRounds && takenA <= shootoutRounds {
+		leftH, leftA := shootoutRounds-takenH, shootoutRounds-takenA
+		switch {
+		case s.HomeScore+leftH < s.AwayScore:
+			s.Winner = away
+		case s.AwayScore+leftA < s.HomeScore:
+			s.Winner = home
+		}
+	} else if takenH == takenA && s.HomeScore != s.AwayScore {
+		s.Winner = home
+		if s.AwayScore > s.HomeScore {
+			s.Winner = away
+		}
+	}
+	s.Completed = s.Winner != ""
+}
+
+// Level reports whether the match score is a draw.
+func (m Match) Level() bool {
+	return m.HomeGoals == m.AwayGoals
+}
+
+// Winner is the team that won the match: on the result, otherwise by shootout.
+// Returns "" for a draw or an undecided shootout.
+func (m Match) Winner() string {
+	home, away := m.Result()
+	switch {
+	case home > away:
+		return m.HomeCode
+	case away > home:
+		return m.AwayCode
+	case m.Shootout != nil && m.Shootout.Completed:
+		return m.Shootout.Winner
+	}
+	return ""
+}
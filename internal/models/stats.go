@@ -0,0 +1,111 @@
+package models
+
+// MatchStats are the aggregated numbers behind GET /stats.
+type MatchStats struct {
+	Total    int                 `bson:"total" json:"total"`
+	ByStatus map[MatchStatus]int `bson:"byStatus" json:"byStatus"`
+
+	// Goal stats only cover matches that were played out (finished); an
+	// awarded score was never scored on the pitch.
+	Played          int            `bson:"played" json:"played"`
+	Goals           int            `bson:"goals" json:"goals"`
+	GoalsByType     map[string]int `bson:"goalsByType" json:"goalsByType"` // legacy goals without a type count as open play
+	PenaltiesMissed int            `bson:"penaltiesMissed" json:"penaltiesMissed"`
+}
+
+// PlayerTotal is what the events of some matches credit one player with.
+// A player is told apart by PlayerKey; name and team are the latest seen.
+// Matches and Minutes are the player's appearances (see Match.Appearances).
+type PlayerTotal struct {
+	PlayerID  string `bson:"playerId,omitempty" json:"playerId,omitempty"`
+	Player    string `bson:"player" json:"player"`
+	TeamCode  string `bson:"teamCode" json:"teamCode"`
+	Goals     int    `bson:"goals" json:"goals"` // own goals never count
+	Penalties int    `bson:"penalties" json:"penalties"`
+	Assists   int    `bson:"assists" json:"assists"`
+	Matches   int    `bson:"matches" json:"matches"`
+	Minutes   int    `bson:"minutes" json:"minutes"`
+}
+
+// Appearance is one player's part in a match.
+type Appearance struct {
+	PlayerID string
+	Player   string
+	TeamCode string
+	Minutes  int
+}
+
+// Length is the playing time of m in minutes: 120 once it went to extra
+// time, else 90.
+func (m Match) Length() int {
+	for _, p := range m.Periods {
+		if p.To == PeriodExtraTime1 {
+			return 120
+		}
+	}
+	for _, e := range m.Events {
+		if e.Minute > 90 {
+			return 120 // extra time of a match recorded without periods
+		}
+	}
+	return 90
+}
+
+// Appearances lists the players who took part in m, in the order they are
+// first named. Matches have no line-ups, so a player appears when an event
+// names the player, a sub-in included, and plays from kick-off or the minute
+// subbed on to the final whistle, or the minute subbed off or sent off.
+func (m Match) Appearances() []Appearance {
+	length := m.Length()
+	type span struct {
+		Appearance
+		from, to int
+	}
+	var spans []*span
+	byKey := map[string]*span{}
+	named := func(id, name, team string) *span {
+		if id == "" && name == "" {
+			return nil
+		}
+		key := PlayerKey(id, name, team)
+		s, ok := byKey[key]
+		if !ok {
+			s = &span{Appearance: Appearance{PlayerID: id, TeamCode: team}, to: length}
+			byKey[key] = s
+			spans = append(spans, s)
+		}
+		if s.Player == "" {
+			s.Player = name
+		}
+		return s
+	}
+
+	for _, e := range m.Events {
+		switch e.Type {
+		case EventSub:
+			if s := named(e.PlayerOutID, e.PlayerOut, e.TeamCode); s != nil {
+				s.to = min(s.to, e.Minute)
+			}
+			if s := named(e.PlayerInID, e.PlayerIn, e.TeamCode); s != nil {
+				s.from = max(s.from, e.Minute)
+			}
+		case EventCard:
+			s := named(e.PlayerID, e.Player, e.TeamCode)
+			if s != nil && e.CardColor == "red" {
+				s.to = min(s.to, e.Minute)
+			}
+		default:
+			named(e.PlayerID, e.Player, e.TeamCode)
+			if e.Type == EventGoal {
+				named(e.AssistID, e.Assist, e.TeamCode)
+			}
+		}
+	}
+
+	out := make([]Appearance, len(spans))
+	for i, s := range spans {
+		out[i] = s.Appearance
+		out[i].Minutes = max(s.to-s.from, 0)
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// TableRow is one team's line in a league table.
+type TableRow struct {
+	TeamCode   string `bson:"teamCode" json:"teamCode"`
+	TeamName   string `bson:"teamName" json:"teamName"`
+	League     string `bson:"league" json:"league"`
+	IsFavorite bool   `bson:"-" json:"isFavorite"`
+
+	P   int `bson:"played" json:"played"`
+	W   int `bson:"wins" json:"wins"`
+	D   int `bson:"draws" json:"draws"`
+	L   int `bson:"losses" json:"losses"`
+	GF  int `bson:"goalsFor" json:"goalsFor"`
+	GA  int `bson:"goalsAgainst" json:"goalsAgainst"`
+	GD  int `bson:"goalDiff" json:"goalDiff"`
+	Pts int `bson:"points" json:"points"` // includes Bonus
+
+	Bonus    int `bson:"bonusPoints" json:"bonusPoints"`
+	AwayGF   int `bson:"awayGoalsFor" json:"awayGoalsFor"`
+	FairPlay int `bson:"fairPlayPoints" json:"fairPlayPoints"` // yellow 1, red 3; lower is better
+
+	Form    []FormResult `bson:"form,omitempty" json:"form,omitempty"` // last five, oldest first; none before the first result
+	Streaks Streaks      `bson:"streaks" json:"streaks"`
+
+	// Live table only (GET /table?live=true): the team is playing now, and how
+	// its place and points move if the live scores stand. Up is positive.
+	Playing        bool `bson:"-" json:"playing,omitempty"`
+	PositionChange int  `bson:"-" json:"positionChange,omitempty"`
+	PointsChange   int  `bson:"-" json:"pointsChange,omitempty"`
+}
+
+// Table views: which side of each match counts.
+const (
+	ViewOverall = "overall"
+	ViewHome    = "home"
+	ViewAway    = "away"
+)
+
+// FormResult is one entry of the form guide.
+type FormResult struct {
+	Result   string `bson:"result" json:"result"` // W | D | L
+	MatchKey string `bson:"matchKey" json:"matchKey"`
+	Opponent string `bson:"opponent" json:"opponent"`
+	Home     bool   `bson:"home" json:"home"`
+	Score    string `bson:"score" json:"score"` // own goals first, e.g. 2-1
+}
+
+// Streaks are the current runs, counted back from the latest match.
+type Streaks struct {
+	Unbeaten   int `bson:"unbeaten" json:"unbeaten"`
+	Winless    int `bson:"winless" json:"winless"`
+	Scoring    int `bson:"scoring" json:"scoring"`
+	CleanSheet int `bson:"cleanSheet" json:"cleanSheet"`
+}
+
+// ResultLine is what a league table needs from one match result. The
+// standings projection keeps these instead of whole matches, and
+// MatchStore.ResultLines reads only these fields.
+type ResultLine struct {
+	MatchKey       string    `bson:"matchKey" json:"matchKey"`
+	Season         string    `bson:"season,omitempty" json:"season,omitempty"`
+	DateTime       time.Time `bson:"dateTime" json:"dateTime"`
+	ResultAt       time.Time `bson:"resultAt" json:"resultAt"`
+	Matchday       int       `bson:"matchday,omitempty" json:"matchday,omitempty"`
+	HomeCode       string    `bson:"homeCode" json:"homeCode"`
+	AwayCode       string    `bson:"awayCode" json:"awayCode"`
+	HomeGoals      int       `bson:"homeGoals" json:"homeGoals"`
+	AwayGoals      int       `bson:"awayGoals" json:"awayGoals"`
+	ShootoutWinner string    `bson:"shootoutWinner,omitempty" json:"shootoutWinner,omitempty"`
+	HomeFairPlay   int       `bson:"homeFairPlay,omitempty" json:"homeFairPlay,omitempty"`
+	AwayFairPlay   int       `bson:"awayFairPlay,omitempty" json:"awayFairPlay,omitempty"`
+}
+
+// Standings is the stored table of one league season, kept up to date as
+// results come in (see package projection).
+type Standings struct {
+	ID      string       `bson:"_id" json:"id"` // StandingsID(league, season)
+	League  string       `bson:"league" json:"league"`
+	Season  string       `bson:"season" json:"season"` // "" for matches without a season
+	Teams   []Team       `bson:"teams" json:"teams"`
+	Lines   []ResultLine `bson:"lines" json:"-"`
+	Rows    []TableRow   `bson:"rows" json:"rows"`
+	RulesAt time.Time    `bson:"rulesAt" json:"rulesAt"` // LeagueRules.UpdatedAt the rows were scored with
+	Version int64        `bson:"version" json:"version"`
+
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+	RebuiltAt time.Time `bson:"rebuiltAt" json:"rebuiltAt"`
+}
+
+func StandingsID(league, season string) string {
+	if season == "" {
+		season = "-"
+	}
+	return league + ":" + season
+}
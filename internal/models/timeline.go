@@ -0,0 +1,95 @@
+package models
+
+import "sort"
+
+// timelineOrder is the order of the periods in a timeline.
+var timelineOrder = []MatchPeriod{
+	PeriodFirstHalf, PeriodHalfTime, PeriodSecondHalf,
+	PeriodExtraTime1, PeriodExtraTime2, PeriodPenalties,
+}
+
+// TimelinePeriod is the events of one period in match time order.
+type TimelinePeriod struct {
+	Period MatchPeriod  `json:"period"`
+	Events []MatchEvent `json:"events"`
+}
+
+// EventCounts sums up the events of one team in a match.
+type EventCounts struct {
+	Goals           int `json:"goals"`    // credited to the team, own goals of the opponent included
+	OwnGoals        int `json:"ownGoals"` // scored by the team's players for the opponent
+	YellowCards     int `json:"yellowCards"`
+	RedCards        int `json:"redCards"`
+	Subs            int `json:"subs"`
+	PenaltiesMissed int `json:"penaltiesMissed"`
+	Injuries        int `json:"injuries"`
+}
+
+// Timeline groups the events of m by period; periods without events are left
+// out. Events recorded without a period (legacy) are placed by their minute.
+func (m Match) Timeline() []TimelinePeriod {
+	events := append([]MatchEvent{}, m.Events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Before(events[j]) })
+
+	byPeriod := map[MatchPeriod][]MatchEvent{}
+	for _, e := range events {
+		p := e.Period
+		if p == PeriodNotStarted {
+			p = PeriodOfMinute(e.Minute)
+		}
+		byPeriod[p] = append(byPeriod[p], e)
+	}
+	out := []TimelinePeriod{}
+	for _, p := range timelineOrder {
+		if len(byPeriod[p]) > 0 {
+			out = append(out, TimelinePeriod{Period: p, Events: byPeriod[p]})
+		}
+	}
+	return out
+}
+
+// PeriodOfMinute is the playing period a match minute falls in (45+2 is
+// still the first half).
+func PeriodOfMinute(minute int) MatchPeriod {
+	switch {
+	case minute <= 45:
+		return PeriodFirstHalf
+	case minute <= 90:
+		return PeriodSecondHalf
+	case minute <= 105:
+		return PeriodExtraTime1
+	}
+	return PeriodExtraTime2
+}
+
+// Counts sums up the events of team code in m.
+func (m Match) Counts(code string) EventCounts {
+	var c EventCounts
+	for _, e := range m.Events {
+		if m.ScoringTeam(e) == code {
+			c.Goals++
+		}
+		if e.TeamCode != code {
+			continue
+		}
+		switch e.Type {
+		case EventGoal:
+			if e.GoalType == GoalOwnGoal {
+				c.OwnGoals++
+			}
+		case EventCard:
+			if e.CardColor == "red" {
+				c.RedCards++
+			} else {
+				c.YellowCards++
+			}
+		case EventSub:
+			c.Subs++
+		case EventPenaltyMissed:
+			c.PenaltiesMissed++
+		case EventInjury:
+			c.Injuries++
+		}
+	}
+	return c
+}
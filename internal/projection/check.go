@@ -0,0 +1,123 @@
+package projection
+
+import (
+	"context"
+	"reflect"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/standings"
+)
+
+type Mismatch struct {
+	ID       string   `json:"id"`
+	Missing  bool     `json:"missing,omitempty"` // nothing stored although there are results
+	Added    []string `json:"added,omitempty"`   // results the stored standings lack
+	Removed  []string `json:"removed,omitempty"` // stored results that no longer count
+	Changed  []string `json:"changed,omitempty"` // results stored with another score, date, ...
+	Rows     bool     `json:"rows"`              // the stored table differs from the recomputed one
+	Repaired bool     `json:"repaired"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type Report struct {
+	Checked    int        `json:"checked"`
+	Mismatches []Mismatch `json:"mismatches"`
+	Repair     bool       `json:"repair"`
+}
+
+// Check recomputes every standings of league ("" for every league) from the
+// matches and reports where the stored projection disagrees. With repair=true
+// those standings are rebuilt.
+func (p *Projector) Check(ctx context.Context, league string, repair bool) (Report, error) {
+	keys, err := p.keys(ctx, league)
+	if err != nil {
+		return Report{}, err
+	}
+
+	rep := Report{Checked: len(keys), Mismatches: []Mismatch{}, Repair: repair}
+	for _, k := range keys {
+		want, err := p.compute(ctx, k.league, k.season)
+		if err != nil {
+			return rep, err
+		}
+		rules, err := p.leagueRules(ctx, k.league)
+		if err != nil {
+			return rep, err
+		}
+		want.Rows = standings.BuildLines(want.Teams, want.Lines, rules, models.ViewOverall)
+
+		got, found, err := p.store.Find(ctx, want.ID)
+		if err != nil {
+			return rep, err
+		}
+		mm := Mismatch{ID: want.ID, Missing: !found && len(want.Lines) > 0}
+		if found {
+			mm.Added, mm.Removed, mm.Changed = diffLines(got.Lines, want.Lines)
+			mm.Rows = !SameRows(got.Rows, want.Rows)
+		} else {
+			mm.Rows = len(want.Lines) > 0
+		}
+		if !mm.Missing && !mm.Rows && len(mm.Added)+len(mm.Removed)+len(mm.Changed) == 0 {
+			continue
+		}
+
+		if repair {
+			if _, err := p.Rebuild(ctx, k.league, k.season); err != nil {
+				mm.Error = err.Error()
+			} else {
+				mm.Repaired = true
+			}
+		}
+		rep.Mismatches = append(rep.Mismatches, mm)
+	}
+	return rep, nil
+}
+
+// diffLines compares the stored lines with the recomputed ones by matchKey.
+func diffLines(stored, want []models.ResultLine) (added, removed, changed []string) {
+	byKey := make(map[string]models.ResultLine, len(stored))
+	for _, l := range stored {
+		byKey[l.MatchKey] = l
+	}
+	for _, l := range want {
+		old, ok := byKey[l.MatchKey]
+		switch {
+		case !ok:
+			added = append(added, l.MatchKey)
+		case !sameLine(old, l):
+			changed = append(changed, l.MatchKey)
+		}
+		delete(byKey, l.MatchKey)
+	}
+	for _, l := range stored {
+		if _, ok := byKey[l.MatchKey]; ok {
+			removed = append(removed, l.MatchKey)
+		}
+	}
+	return added, removed, changed
+}
+
+// sameLine compares two lines regardless of how their times were decoded.
+func sameLine(a, b models.ResultLine) bool {
+	a.DateTime, b.DateTime = a.DateTime.UTC(), b.DateTime.UTC()
+	a.ResultAt, b.ResultAt = a.ResultAt.UTC(), b.ResultAt.UTC()
+	return a == b
+}
+
+// SameRows reports whether two tables are equal row by row (an empty form
+// guide equals a missing one).
+func SameRows(a, b []models.TableRow) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		if len(x.Form) == 0 && len(y.Form) == 0 {
+			x.Form, y.Form = nil, nil
+		}
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+// Package projection keeps the standings collection: one stored table per
+// league season, updated as results come in and rebuilt from the matches on
+// demand.
+package projection
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/standings"
+)
+
+// maxRetries bounds how often a write is retried after a concurrent update.
+const maxRetries = 5
+
+type Projector struct {
+	store   repository.StandingsStore
+	matches repository.MatchStore
+	teams   repository.TeamStore
+	seasons repository.SeasonStore
+	rules   repository.RulesStore
+}
+
+func New(store repository.StandingsStore, matches repository.MatchStore, teams repository.TeamStore, seasons repository.SeasonStore, rules repository.RulesStore) *Projector {
+	return &Projector{store: store, matches: matches, teams: teams, seasons: seasons, rules: rules}
+}
+
+// Apply folds the result of m into the standings of its league season, or
+// corrects it when m is already there. Matches without a result are ignored.
+// The first result of a season builds its standings from all matches so far.
+func (p *Projector) Apply(ctx context.Context, m models.Match) error {
+	if !m.Status.HasResult() {
+		return nil
+	}
+	league, err := p.leagueOf(ctx, m)
+	if err != nil || league == "" {
+		return err
+	}
+
+	line := standings.LineOf(m)
+	id := models.StandingsID(league, m.Season)
+	for range maxRetries {
+		s, found, err := p.store.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+		if found {
+			if !setLine(&s, line) {
+				return nil
+			}
+			err = p.save(ctx, s, false)
+		} else {
+			_, err = p.Rebuild(ctx, league, m.Season)
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return err
+		}
+	}
+	return repository.ErrConflict
+}
+
+// ApplyKeys reloads the matches with the given keys and applies each of them.
+func (p *Projector) ApplyKeys(ctx context.Context, keys ...string) error {
+	for _, k := range keys {
+		m, found, err := p.matches.FindByKey(ctx, k)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err := p.Apply(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild recomputes the standings of league season ("" for matches without
+// a season) from the matches and stores them.
+func (p *Projector) Rebuild(ctx context.Context, league, season string) (models.Standings, error) {
+	for range maxRetries {
+		s, err := p.compute(ctx, league, season)
+		if err != nil {
+			return s, err
+		}
+		old, found, err := p.store.Find(ctx, s.ID)
+		if err != nil {
+			return s, err
+		}
+		if found {
+			s.Version = old.Version
+		}
+		if err := p.save(ctx, s, true); !errors.Is(err, repository.ErrConflict) {
+			return s, err
+		}
+	}
+	return models.Standings{}, repository.ErrConflict
+}
+
+// RebuildAll rebuilds every standings of league ("" for every league) and
+// returns their ids.
+func (p *Projector) RebuildAll(ctx context.Context, league string) ([]string, error) {
+	keys, err := p.keys(ctx, league)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		s, err := p.Rebuild(ctx, k.league, k.season)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, s.ID)
+	}
+	return ids, nil
+}
+
+// Rescore recomputes the stored tables of league under its current rules,
+// e.g. after the rules were changed. The results are kept.
+func (p *Projector) Rescore(ctx context.Context, league string) error {
+	list, err := p.store.List(ctx, league)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		if err := p.resave(ctx, s, func(*models.Standings) {}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTeam puts the new details of t into the stored standings that list
+// it, e.g. after a rename, and rescores them.
+func (p *Projector) UpdateTeam(ctx context.Context, t models.Team) error {
+	list, err := p.store.List(ctx, t.League)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		if !slices.ContainsFunc(s.Teams, func(o models.Team) bool { return o.Code == t.Code }) {
+			continue
+		}
+		err := p.resave(ctx, s, func(s *models.Standings) {
+			for i := range s.Teams {
+				if s.Teams[i].Code == t.Code {
+					s.Teams[i] = t
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resave applies edit to s and saves it, reloading s after a concurrent update.
+func (p *Projector) resave(ctx context.Context, s models.Standings, edit func(*models.Standings)) error {
+	for try := 0; ; try++ {
+		edit(&s)
+		err := p.save(ctx, s, false)
+		if !errors.Is(err, repository.ErrConflict) || try == maxRetries {
+			return err
+		}
+		var found bool
+		if s, found, err = p.store.Find(ctx, s.ID); err != nil || !found {
+			return err
+		}
+	}
+}
+
+// BuildIfEmpty builds every standings when none are stored yet, e.g. on the
+// first start after the projection was introduced.
+func (p *Projector) BuildIfEmpty(ctx context.Context) error {
+	stored, err := p.store.List(ctx, "")
+	if err != nil || len(stored) > 0 {
+		return err
+	}
+	_, err = p.RebuildAll(ctx, "")
+	return err
+}
+
+// Lines returns the stored result lines of league ("" for every league),
+// optionally only those of seasons ("" stands for matches without a season).
+func (p *Projector) Lines(ctx context.Context, league string, seasons ...string) ([]models.ResultLine, error) {
+	list, err := p.store.List(ctx, league, seasons...)
+	if err != nil {
+		return nil, err
+	}
+	var out []models.ResultLine
+	for _, s := range list {
+		out = append(out, s.Lines...)
+	}
+	return out, nil
+}
+
+// Table returns the stored overall table of one league season. Rows scored
+// under older rules are recomputed from the stored lines. ok is false when
+// nothing is stored for it.
+func (p *Projector) Table(ctx context.Context, league, season string, rules models.LeagueRules) (rows []models.TableRow, ok bool, err error) {
+	s, found, err := p.store.Find(ctx, models.StandingsID(league, season))
+	if err != nil || !found {
+		return nil, false, err
+	}
+	if !s.RulesAt.Equal(rules.UpdatedAt) {
+		return standings.BuildLines(s.Teams, s.Lines, rules, models.ViewOverall), true, nil
+	}
+	return s.Rows, true, nil
+}
+
+// save scores s under its league's current rules and stores it as the next version.
+func (p *Projector) save(ctx context.Context, s models.Standings, rebuilt bool) error {
+	rules, err := p.leagueRules(ctx, s.League)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	s.Rows = standings.BuildLines(s.Teams, s.Lines, rules, models.ViewOverall)
+	s.RulesAt = rules.UpdatedAt
+	s.Version++
+	s.UpdatedAt = now
+	if rebuilt {
+		s.RebuiltAt = now
+	}
+	return p.store.Save(ctx, s)
+}
+
+// compute builds the standings of league season from the matches, unsaved and
+// without a version.
+func (p *Projector) compute(ctx context.Context, league, season string) (models.Standings, error) {
+	s := models.Standings{ID: models.StandingsID(league, season), League: league, Season: season}
+
+	var err error
+	if season != "" {
+		ss, found, ferr := p.seasons.Find(ctx, season)
+		if ferr != nil {
+			return s, ferr
+		}
+		if !found || ss.League != league {
+			return s, repository.ErrNotFound
+		}
+		s.Teams, err = SeasonTeams(ctx, p.teams, ss)
+	} else {
+		s.Teams, err = p.teams.ListByLeague(ctx, league)
+	}
+	if err != nil {
+		return s, err
+	}
+
+	lines, err := p.matches.ResultLines(ctx, season)
+	if err != nil {
+		return s, err
+	}
+	// without a season a match belongs to its home team's league
+	inLeague := make(map[string]bool, len(s.Teams))
+	for _, t := range s.Teams {
+		inLeague[t.Code] = true
+	}
+	s.Lines = []models.ResultLine{}
+	for _, l := range lines {
+		if season == "" && !inLeague[l.HomeCode] {
+			continue
+		}
+		s.Lines = append(s.Lines, l)
+	}
+	sortLines(s.Lines)
+	return s, nil
+}
+
+// key names one league season of the projection.
+type key struct{ league, season string }
+
+// keys lists the league seasons of league ("" for every league) that have
+// results or stored standings.
+func (p *Projector) keys(ctx context.Context, league string) ([]key, error) {
+	seen := map[key]bool{}
+	var out []key
+	add := func(k key) {
+		if k.league != "" && (league == "" || k.league == league) && !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+
+	stored, err := p.store.List(ctx, league)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stored {
+		add(key{s.League, s.Season})
+	}
+
+	lines, err := p.matches.ResultLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := p.seasons.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	seasonLeague := make(map[string]string, len(seasons))
+	for _, s := range seasons {
+		seasonLeague[s.ID] = s.League
+	}
+	teams, err := p.teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teamLeague := make(map[string]string, len(teams))
+	for _, t := range teams {
+		teamLeague[t.Code] = t.League
+	}
+	for _, l := range lines {
+		lg := teamLeague[l.HomeCode]
+		if l.Season != "" {
+			lg = seasonLeague[l.Season]
+		}
+		add(key{lg, l.Season})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].league != out[j].league {
+			return out[i].league < out[j].league
+		}
+		return out[i].season < out[j].season
+	})
+	return out, nil
+}
+
+// leagueOf is the league whose standings m counts in: its season's, else its
+// home team's.
+func (p *Projector) leagueOf(ctx context.Context, m models.Match) (string, error) {
+	if m.Season != "" {
+		s, found, err := p.seasons.Find(ctx, m.Season)
+		if err != nil || found {
+			return s.League, err
+		}
+	}
+	t, _, err := p.teams.Find(ctx, m.HomeCode)
+	return t.League, err
+}
+
+// leagueRules returns the stored rules of league, or the defaults.
+func (p *Projector) leagueRules(ctx context.Context, league string) (models.LeagueRules, error) {
+	rules, found, err := p.rules.Find(ctx, league)
+	if err != nil {
+		return models.LeagueRules{}, err
+	}
+	if !found {
+		return models.DefaultRules(league), nil
+	}
+	return rules, nil
+}
+
+// SeasonTeams loads the participating teams of s.
+func SeasonTeams(ctx context.Context, teams repository.TeamStore, s models.Season) ([]models.Team, error) {
+	all, err := teams.ListByLeague(ctx, s.League)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Team, 0, len(s.Teams))
+	for _, t := range all {
+		if s.HasTeam(t.Code) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// setLine puts l into s in place of the line of the same match. It reports
+// whether anything changed.
+func setLine(s *models.Standings, l models.ResultLine) bool {
+	for i, old := range s.Lines {
+		if old.MatchKey == l.MatchKey {
+			if sameLine(old, l) {
+				return false
+			}
+			s.Lines[i] = l
+			sortLines(s.Lines)
+			return true
+		}
+	}
+	s.Lines = append(s.Lines, l)
+	sortLines(s.Lines)
+	return true
+}
+
+func sortLines(lines []models.ResultLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		if !lines[i].DateTime.Equal(lines[j].DateTime) {
+			return lines[i].DateTime.Before(lines[j].DateTime)
+		}
+		return lines[i].MatchKey < lines[j].MatchKey
+	})
+}
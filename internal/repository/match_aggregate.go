@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ResultLines computes the table line of every match with a result on the
+// server, so the events arrays never leave MongoDB. It is the pipeline twin of
+// standings.LineOf.
+func (r *MatchRepo) ResultLines(ctx context.Context, seasons ...string) ([]models.ResultLine, error) {
+	awarded := bson.M{"$and": bson.A{
+		bson.M{"$eq": bson.A{"$status", models.Awarded}},
+		bson.M{"$gt": bson.A{"$award", nil}},
+	}}
+	fairPlay := func(side string) bson.M {
+		return bson.M{"$sum": bson.M{"$map": bson.M{
+			"input": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$events", bson.A{}}},
+				"cond": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$$this.type", models.EventCard}},
+					bson.M{"$eq": bson.A{"$$this.teamCode", side}},
+				}},
+			}},
+			"in": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{"$$this.cardColor", "yellow"}}, "then": models.FairPlayYellow},
+					bson.M{"case": bson.M{"$eq": bson.A{"$$this.cardColor", "red"}}, "then": models.FairPlayRed},
+				},
+				"default": 0,
+			}},
+		}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: seasonFilter(bson.M{"status": bson.M{"$in": bson.A{models.Finished, models.Awarded}}}, seasons)}},
+		{{Key: "$sort", Value: bson.D{{Key: "dateTime", Value: 1}, {Key: "matchKey", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"matchKey": 1,
+			"season":   1,
+			"dateTime": 1,
+			"matchday": 1,
+			"homeCode": 1,
+			"awayCode": 1,
+			"resultAt": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{awarded, bson.M{"$gt": bson.A{"$awardedAt", nil}}}},
+				"$awardedAt",
+				bson.M{"$ifNull": bson.A{"$finishedAt", "$dateTime"}},
+			}},
+			"homeGoals": bson.M{"$cond": bson.A{awarded, "$award.homeGoals", "$homeGoals"}},
+			"awayGoals": bson.M{"$cond": bson.A{awarded, "$award.awayGoals", "$awayGoals"}},
+			"shootoutWinner": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$homeGoals", "$awayGoals"}},
+					bson.M{"$eq": bson.A{"$shootout.completed", true}},
+				}},
+				"$shootout.winner",
+				"$$REMOVE",
+			}},
+			"homeFairPlay": fairPlay("$homeCode"),
+			"awayFairPlay": fairPlay("$awayCode"),
+		}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.ResultLine
+	for cur.Next(ctx) {
+		var l models.ResultLine
+		if err := cur.Decode(&l); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, cur.Err()
+}
+
+// Stats counts matches by status and the goals of finished matches in one
+// aggregation; only the numbers come back.
+func (r *MatchRepo) Stats(ctx context.Context, seasons ...string) (models.MatchStats, error) {
+	finished := bson.D{{Key: "$match", Value: bson.M{"status": models.Finished}}}
+	goalType := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$events.goalType", ""}}, ""}},
+		"$events.goalType",
+		models.GoalOpenPlay,
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: seasonFilter(bson.M{}, seasons)}},
+		{{Key: "$facet", Value: bson.M{
+			"byStatus": bson.A{
+				bson.M{"$group": bson.M{"_id": "$status", "n": bson.M{"$sum": 1}}},
+			},
+			"played": bson.A{
+				finished,
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"n":     bson.M{"$sum": 1},
+					"goals": bson.M{"$sum": bson.M{"$add": bson.A{"$homeGoals", "$awayGoals"}}},
+				}},
+			},
+			"events": bson.A{
+				finished,
+				bson.M{"$project": bson.M{"events": bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$events", bson.A{}}},
+					"cond":  bson.M{"$in": bson.A{"$$this.type", bson.A{models.EventGoal, models.EventPenaltyMissed}}},
+				}}}},
+				bson.M{"$unwind": "$events"},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{"$events.type", models.EventGoal}},
+						goalType,
+						models.EventPenaltyMissed,
+					}},
+					"n": bson.M{"$sum": 1},
+				}},
+			},
+		}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.MatchStats{}, err
+	}
+	defer cur.Close(ctx)
+
+	type count struct {
+		ID    string `bson:"_id"`
+		N     int    `bson:"n"`
+		Goals int    `bson:"goals"`
+	}
+	var res []struct {
+		ByStatus []count `bson:"byStatus"`
+		Played   []count `bson:"played"`
+		Events   []count `bson:"events"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return models.MatchStats{}, err
+	}
+
+	st := models.MatchStats{ByStatus: map[models.MatchStatus]int{}, GoalsByType: map[string]int{}}
+	if len(res) == 0 {
+		return st, nil
+	}
+	for _, c := range res[0].ByStatus {
+		st.ByStatus[models.MatchStatus(c.ID)] = c.N
+		st.Total += c.N
+	}
+	for _, c := range res[0].Played {
+		st.Played, st.Goals = c.N, c.Goals
+	}
+	for _, c := range res[0].Events {
+		if c.ID == models.EventPenaltyMissed {
+			st.PenaltiesMissed = c.N
+			continue
+		}
+		st.GoalsByType[c.ID] = c.N
+	}
+	return st, nil
+}
+
+// PlayerTotals credits players on the server, the pipeline twin of
+// models.Match.Appearances plus the goal count: every event becomes a row
+// per player it names, the rows are grouped per match and player into an
+// appearance, and the appearances per models.PlayerKey.
+func (r *MatchRepo) PlayerTotals(ctx context.Context, q models.MatchQuery) ([]models.PlayerTotal, error) {
+	notOwn := bson.M{"$ne": bson.A{"$$e.goalType", models.GoalOwnGoal}}
+	one := func(cond any) bson.M { return bson.M{"$cond": bson.A{cond, 1, 0}} }
+	// ref is one row: the player named by id and name, played from-to, and
+	// what the event credits
+	ref := func(id, name string, from, to, goals, penalties, assists any) bson.M {
+		return bson.M{
+			"id":        bson.M{"$ifNull": bson.A{"$$e." + id, ""}},
+			"name":      bson.M{"$ifNull": bson.A{"$$e." + name, ""}},
+			"team":      "$$e.teamCode",
+			"from":      from,
+			"to":        to,
+			"goals":     goals,
+			"penalties": penalties,
+			"assists":   assists,
+		}
+	}
+	refs := bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$eq": bson.A{"$$e.type", models.EventSub}}, "then": bson.A{
+				ref("playerOutId", "playerOut", 0, "$$e.minute", 0, 0, 0),
+				ref("playerInId", "playerIn", "$$e.minute", "$length", 0, 0, 0),
+			}},
+			bson.M{"case": bson.M{"$eq": bson.A{"$$e.type", models.EventCard}}, "then": bson.A{
+				ref("playerId", "player", 0, bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$e.cardColor", "red"}}, "$$e.minute", "$length"}}, 0, 0, 0),
+			}},
+			bson.M{"case": bson.M{"$eq": bson.A{"$$e.type", models.EventGoal}}, "then": bson.A{
+				ref("playerId", "player", 0, "$length", one(notOwn), one(bson.M{"$eq": bson.A{"$$e.goalType", models.GoalPenalty}}), 0),
+				ref("assistId", "assist", 0, "$length", 0, 0, one(notOwn)),
+			}},
+		},
+		"default": bson.A{ref("playerId", "player", 0, "$length", 0, 0, 0)},
+	}}
+	length := bson.M{"$cond": bson.A{
+		bson.M{"$or": bson.A{
+			bson.M{"$in": bson.A{models.PeriodExtraTime1, bson.M{"$ifNull": bson.A{"$periods.to", bson.A{}}}}},
+			bson.M{"$gt": bson.A{bson.M{"$max": "$events.minute"}, 90}}, // recorded without periods
+		}},
+		120,
+		90,
+	}}
+	key := bson.M{"$cond": bson.A{
+		bson.M{"$ne": bson.A{"$ref.id", ""}},
+		"$ref.id",
+		bson.M{"$concat": bson.A{"$ref.team", ":", bson.M{"$toLower": "$ref.name"}}},
+	}}
+	// $push skips the missing values, so an event without a name keeps the
+	// name seen before
+	name := bson.M{"$cond": bson.A{bson.M{"$ne": bson.A{"$ref.name", ""}}, "$ref.name", "$$REMOVE"}}
+	last := func(list string) bson.M { return bson.M{"$arrayElemAt": bson.A{list, -1}} }
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: queryFilter(q)}},
+		{{Key: "$set", Value: bson.M{"length": length}}},
+		{{Key: "$project", Value: bson.M{
+			"dateTime": 1,
+			"ref": bson.M{"$reduce": bson.M{
+				"input":        bson.M{"$map": bson.M{"input": bson.M{"$ifNull": bson.A{"$events", bson.A{}}}, "as": "e", "in": refs}},
+				"initialValue": bson.A{},
+				"in":           bson.M{"$concatArrays": bson.A{"$$value", "$$this"}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$ref"}},
+		{{Key: "$match", Value: bson.M{"$or": bson.A{bson.M{"ref.id": bson.M{"$ne": ""}}, bson.M{"ref.name": bson.M{"$ne": ""}}}}}},
+		// one appearance per match and player
+		{{Key: "$group", Value: bson.M{
+			"_id":       bson.M{"match": "$_id", "key": key},
+			"dateTime":  bson.M{"$first": "$dateTime"},
+			"playerId":  bson.M{"$last": "$ref.id"},
+			"names":     bson.M{"$push": name},
+			"teamCode":  bson.M{"$last": "$ref.team"},
+			"from":      bson.M{"$max": "$ref.from"},
+			"to":        bson.M{"$min": "$ref.to"},
+			"goals":     bson.M{"$sum": "$ref.goals"},
+			"penalties": bson.M{"$sum": "$ref.penalties"},
+			"assists":   bson.M{"$sum": "$ref.assists"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "dateTime", Value: 1}, {Key: "_id.match", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$_id.key",
+			"playerId":  bson.M{"$last": "$playerId"},
+			"names":     bson.M{"$push": last("$names")},
+			"teamCode":  bson.M{"$last": "$teamCode"},
+			"matches":   bson.M{"$sum": 1},
+			"minutes":   bson.M{"$sum": bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{"$to", "$from"}}, 0}}},
+			"goals":     bson.M{"$sum": "$goals"},
+			"penalties": bson.M{"$sum": "$penalties"},
+			"assists":   bson.M{"$sum": "$assists"},
+		}}},
+		{{Key: "$set", Value: bson.M{"player": last("$names")}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := []models.PlayerTotal{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Query lists one page of the matches selected by q. next is the position
+// after the page, nil on the last page. Matchdays are stored only when set,
+// so a missing matchday sorts as 0 (MongoDB orders it before numbers).
+func (r *MatchRepo) Query(ctx context.Context, q models.MatchQuery) ([]models.Match, *models.MatchCursor, error) {
+	if q.Sort == "" {
+		q.Sort = models.SortDate
+	}
+	filter := queryFilter(q)
+
+	dir := 1
+	if q.Sort.Desc() {
+		dir = -1
+	}
+	order := bson.D{}
+	if q.Sort.ByMatchday() {
+		order = append(order, bson.E{Key: "matchday", Value: dir})
+	}
+	order = append(order, bson.E{Key: "dateTime", Value: dir}, bson.E{Key: "_id", Value: dir})
+
+	opts := options.Find().SetSort(order)
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit) + 1)
+	}
+	if !q.Events {
+		opts.SetProjection(bson.M{"events": 0})
+	}
+
+	cur, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.Match
+	for cur.Next(ctx) {
+		var m models.Match
+		if err := cur.Decode(&m); err != nil {
+			return nil, nil, err
+		}
+		out = append(out, m)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, nil, err
+	}
+	return pageOf(out, q)
+}
+
+// queryFilter selects the matches of q from q.After on; sort and limit are
+// left to the caller.
+func queryFilter(q models.MatchQuery) bson.M {
+	and := bson.A{}
+	if len(q.Seasons) > 0 {
+		and = append(and, seasonFilter(bson.M{}, q.Seasons))
+		if q.NoSeasonHome != nil {
+			and = append(and, bson.M{"$or": bson.A{
+				bson.M{"season": bson.M{"$nin": bson.A{nil, ""}}},
+				bson.M{"homeCode": bson.M{"$in": q.NoSeasonHome}},
+			}})
+		}
+	}
+	switch {
+	case q.Team != "" && q.Opponent != "":
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{"homeCode": q.Team, "awayCode": q.Opponent},
+			bson.M{"homeCode": q.Opponent, "awayCode": q.Team},
+		}})
+	case q.Team != "":
+		and = append(and, bson.M{"$or": bson.A{bson.M{"homeCode": q.Team}, bson.M{"awayCode": q.Team}}})
+	}
+	if len(q.Statuses) > 0 {
+		and = append(and, bson.M{"status": bson.M{"$in": q.Statuses}})
+	}
+	if !q.From.IsZero() {
+		and = append(and, bson.M{"dateTime": bson.M{"$gte": q.From}})
+	}
+	if !q.To.IsZero() {
+		and = append(and, bson.M{"dateTime": bson.M{"$lt": q.To}})
+	}
+	if q.Matchday > 0 {
+		and = append(and, bson.M{"matchday": q.Matchday})
+	}
+	if q.After != nil {
+		and = append(and, afterFilter(*q.After))
+	}
+	filter := bson.M{}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	return filter
+}
+
+// pageOf cuts the extra match fetched to see whether another page follows.
+func pageOf(list []models.Match, q models.MatchQuery) ([]models.Match, *models.MatchCursor, error) {
+	if q.Limit <= 0 || len(list) <= q.Limit {
+		return list, nil, nil
+	}
+	list = list[:q.Limit]
+	next := models.CursorOf(list[len(list)-1], q.Sort)
+	return list, &next, nil
+}
+
+// afterFilter selects the matches after c in its order (keyset pagination).
+func afterFilter(c models.MatchCursor) bson.M {
+	op := "$gt"
+	if c.Sort.Desc() {
+		op = "$lt"
+	}
+	afterTime := bson.M{"$or": bson.A{
+		bson.M{"dateTime": bson.M{op: c.DateTime}},
+		bson.M{"dateTime": c.DateTime, "_id": bson.M{op: c.ID}},
+	}}
+	if !c.Sort.ByMatchday() {
+		return afterTime
+	}
+
+	var sameDay, afterDay bson.M
+	if c.Matchday == 0 {
+		sameDay = bson.M{"matchday": nil}
+	} else {
+		sameDay = bson.M{"matchday": c.Matchday}
+	}
+	switch {
+	case !c.Sort.Desc():
+		afterDay = bson.M{"matchday": bson.M{"$gt": c.Matchday}}
+	case c.Matchday == 0:
+		afterDay = bson.M{"matchday": bson.M{"$lt": 0}} // nothing sorts before a missing matchday
+	default:
+		afterDay = bson.M{"$or": bson.A{bson.M{"matchday": bson.M{"$lt": c.Matchday}}, bson.M{"matchday": nil}}}
+	}
+	return bson.M{"$or": bson.A{
+		afterDay,
+		bson.M{"$and": bson.A{sameDay, afterTime}},
+	}}
+}
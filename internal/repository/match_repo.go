@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"final-by-me/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -19,20 +21,71 @@ func NewMatchRepo(db *mongo.Database) *MatchRepo {
 }
 
 func (r *MatchRepo) EnsureIndexes(ctx context.Context) error {
-	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "matchKey", Value: 1}},
-		Options: options.Index().SetUnique(true),
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "matchKey", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "season", Value: 1}, {Key: "status", Value: 1}}},
+		// ListByStatus and the result/stats pipelines
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "dateTime", Value: 1}}},
+		// a team's matches in date order
+		{Keys: bson.D{{Key: "homeCode", Value: 1}, {Key: "dateTime", Value: 1}}},
+		{Keys: bson.D{{Key: "awayCode", Value: 1}, {Key: "dateTime", Value: 1}}},
+		// Query: listings in kick-off or round order, per season or overall
+		{Keys: bson.D{{Key: "dateTime", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "season", Value: 1}, {Key: "dateTime", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "season", Value: 1}, {Key: "matchday", Value: 1}, {Key: "dateTime", Value: 1}, {Key: "_id", Value: 1}}},
 	})
 	return err
 }
 
 func (r *MatchRepo) Create(ctx context.Context, m models.Match) (models.Match, error) {
+	if m.ID.IsZero() {
+		m.ID = primitive.NewObjectID()
+	}
 	_, err := r.col.InsertOne(ctx, m)
 	return m, err
 }
 
-func (r *MatchRepo) List(ctx context.Context) ([]models.Match, error) {
-	cur, err := r.col.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "dateTime", Value: 1}}))
+// CreateMany refuses the whole batch if one of its keys exists, then inserts
+// it unordered. A key taken concurrently fails only that insert (ErrConflict).
+func (r *MatchRepo) CreateMany(ctx context.Context, matches []models.Match) (int, error) {
+	if len(matches) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(matches))
+	docs := make([]any, len(matches))
+	for i, m := range matches {
+		if m.ID.IsZero() {
+			m.ID = primitive.NewObjectID()
+		}
+		keys[i] = m.MatchKey
+		docs[i] = m
+	}
+
+	taken, err := r.col.CountDocuments(ctx, bson.M{"matchKey": bson.M{"$in": keys}})
+	if err != nil {
+		return 0, err
+	}
+	if taken > 0 {
+		return 0, ErrConflict
+	}
+
+	res, err := r.col.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	n := 0
+	if res != nil {
+		n = len(res.InsertedIDs)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return n, ErrConflict
+	}
+	return n, err
+}
+
+// List returns all matches, or only those of the given seasons.
+func (r *MatchRepo) List(ctx context.Context, seasons ...string) ([]models.Match, error) {
+	cur, err := r.col.Find(ctx, seasonFilter(bson.M{}, seasons), options.Find().SetSort(bson.D{{Key: "dateTime", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
@@ -61,45 +114,377 @@ func (r *MatchRepo) FindByKey(ctx context.Context, key string) (models.Match, bo
 	return m, true, nil
 }
 
-// Universal event insert (goal increments score)
-func (r *MatchRepo) AddEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent) error {
-	update := bson.M{
-		"$push": bson.M{"events": e},
+func (r *MatchRepo) ExistingKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	if len(keys) == 0 {
+		return out, nil
+	}
+	cur, err := r.col.Find(ctx, bson.M{"matchKey": bson.M{"$in": keys}},
+		options.Find().SetProjection(bson.M{"_id": 0, "matchKey": 1}))
+	if err != nil {
+		return nil, err
 	}
+	defer cur.Close(ctx)
 
-	// If goal -> auto score update
-	if e.Type == "goal" {
-		if e.TeamCode == match.HomeCode {
-			update["$inc"] = bson.M{"homeGoals": 1}
-		} else if e.TeamCode == match.AwayCode {
-			update["$inc"] = bson.M{"awayGoals": 1}
+	for cur.Next(ctx) {
+		var doc struct {
+			MatchKey string `bson:"matchKey"`
 		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		out[doc.MatchKey] = true
+	}
+	return out, cur.Err()
+}
+
+// Universal event insert. The score is recomputed from the events array
+// in the same update, so it can never drift from the goal events.
+func (r *MatchRepo) AddEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"events": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$events", bson.A{}}},
+				bson.A{bson.M{"$literal": e}},
+			}},
+		}}},
+		scoreStage(),
 	}
 
 	_, err := r.col.UpdateOne(ctx,
 		bson.M{"matchKey": key, "status": bson.M{"$in": []models.MatchStatus{models.Scheduled, models.Live}}},
-		update,
+		pipeline,
 	)
 	return err
 }
-func (r *MatchRepo) SetStatus(ctx context.Context, key string, status models.MatchStatus) error {
-	_, err := r.col.UpdateOne(ctx,
-		bson.M{"matchKey": key},
-		bson.M{"$set": bson.M{"status": status}},
+
+// UpdateEvent replaces the event with e.ID and recomputes the score in one update.
+// match is the state the caller validated against; if that event changed
+// meanwhile nothing is written and ErrConflict is returned.
+func (r *MatchRepo) UpdateEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent, c *models.EventCorrection) error {
+	old, ok := match.FindEvent(e.ID)
+	if !ok {
+		return ErrNotFound
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"events": bson.M{"$map": bson.M{
+				"input": "$events",
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$$this.id", e.ID}},
+					bson.M{"$literal": e},
+					"$$this",
+				}},
+			}},
+		}}},
+		scoreStage(),
+	}
+	if c != nil {
+		pipeline = append(pipeline, correctionStage(*c))
+	}
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"matchKey": key, "status": match.Status, "events": sameEvent(old)}, pipeline)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// DeleteEvent removes the event with id and recomputes the score in the same update.
+func (r *MatchRepo) DeleteEvent(ctx context.Context, key string, match models.Match, id string, c *models.EventCorrection) error {
+	old, ok := match.FindEvent(id)
+	if !ok {
+		return ErrNotFound
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"events": bson.M{"$filter": bson.M{
+				"input": "$events",
+				"cond":  bson.M{"$ne": bson.A{"$$this.id", id}},
+			}},
+		}}},
+		scoreStage(),
+	}
+	if c != nil {
+		pipeline = append(pipeline, correctionStage(*c))
+	}
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"matchKey": key, "status": match.Status, "events": sameEvent(old)}, pipeline)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// RecomputeScore rewrites homeGoals/awayGoals from the stored events.
+func (r *MatchRepo) RecomputeScore(ctx context.Context, key string) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{"matchKey": key}, mongo.Pipeline{scoreStage()})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// correctionStage appends c to the audit trail of the match.
+func correctionStage(c models.EventCorrection) bson.D {
+	return bson.D{{Key: "$set", Value: bson.M{
+		"corrections": bson.M{"$concatArrays": bson.A{
+			bson.M{"$ifNull": bson.A{"$corrections", bson.A{}}},
+			bson.A{bson.M{"$literal": c}},
+		}},
+	}}}
+}
+
+// sameEvent matches the stored event only if the fields that decide the score are unchanged
+func sameEvent(e models.MatchEvent) bson.M {
+	m := bson.M{"id": e.ID, "type": e.Type, "teamCode": e.TeamCode, "goalType": e.GoalType}
+	if e.GoalType == "" {
+		m["goalType"] = nil // matches legacy events without the field
+	}
+	return bson.M{"$elemMatch": m}
+}
+
+// scoreStage is the server-side twin of models.Match.Rescore.
+// A goal counts for its teamCode, an own goal for the other side; the
+// imported score of a scoreOnly match is kept.
+func scoreStage() bson.D {
+	goalsFor := func(side, other string) bson.M {
+		isOwn := bson.M{"$eq": bson.A{"$$this.goalType", models.GoalOwnGoal}}
+		return bson.M{"$size": bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$events", bson.A{}}},
+			"cond": bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$$this.type", models.EventGoal}},
+				bson.M{"$or": bson.A{
+					bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$this.teamCode", side}}, bson.M{"$not": bson.A{isOwn}}}},
+					bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$this.teamCode", other}}, isOwn}},
+				}},
+			}},
+		}}}
+	}
+	scoreOnly := bson.M{"$eq": bson.A{"$scoreOnly", true}}
+	return bson.D{{Key: "$set", Value: bson.M{
+		"homeGoals": bson.M{"$cond": bson.A{scoreOnly, "$homeGoals", goalsFor("$homeCode", "$awayCode")}},
+		"awayGoals": bson.M{"$cond": bson.A{scoreOnly, "$awayGoals", goalsFor("$awayCode", "$homeCode")}},
+	}}}
+}
+
+// SetPeriod moves the match from change.From to change.To and records the change.
+// Returns ErrConflict if the match is no longer in change.From.
+func (r *MatchRepo) SetPeriod(ctx context.Context, key string, change models.PeriodChange) error {
+	filter := bson.M{"matchKey": key, "period": change.From}
+	if change.From == models.PeriodNotStarted {
+		filter["period"] = bson.M{"$in": bson.A{nil, ""}}
+	}
+
+	res, err := r.col.UpdateOne(ctx, filter, bson.M{
+		"$set":  bson.M{"period": change.To},
+		"$push": bson.M{"periods": change},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// SetShootout stores the shootout after one more kick. It only applies if the
+// stored shootout still has len(s.Kicks)-1 kicks, otherwise ErrConflict.
+func (r *MatchRepo) SetShootout(ctx context.Context, key string, s models.Shootout) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{
+			"matchKey": key,
+			"$expr": bson.M{"$eq": bson.A{
+				bson.M{"$size": bson.M{"$ifNull": bson.A{"$shootout.kicks", bson.A{}}}},
+				len(s.Kicks) - 1,
+			}},
+		},
+		bson.M{"$set": bson.M{"shootout": s}},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
 }
 
-func (r *MatchRepo) Finalize(ctx context.Context, key string) error {
-	_, err := r.col.UpdateOne(ctx,
-		bson.M{"matchKey": key},
-		bson.M{"$set": bson.M{"status": models.Finished}},
+// SetStatus moves the match through the lifecycle. The update only applies
+// while the match is still in change.From (ErrConflict otherwise); it stamps
+// the transition time (kickedOffAt keeps the first kick-off) and appends
+// the change to transitions.
+func (r *MatchRepo) SetStatus(ctx context.Context, key string, change models.StatusChange) error {
+	if err := CheckTransition(change); err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"status": change.To,
+		"transitions": bson.M{"$concatArrays": bson.A{
+			bson.M{"$ifNull": bson.A{"$transitions", bson.A{}}},
+			bson.A{bson.M{"$literal": change}},
+		}},
+	}
+	if f := models.TimestampField(change.To); f != "" {
+		set[f] = change.At
+		if change.To == models.Live {
+			set[f] = bson.M{"$ifNull": bson.A{"$" + f, change.At}}
+		}
+	}
+	if change.To == models.Live {
+		// kick-off starts the first half; resuming keeps the period
+		notStarted := bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$period", ""}}, ""}}
+		set["period"] = bson.M{"$cond": bson.A{notStarted, models.PeriodFirstHalf, "$period"}}
+		set["periods"] = bson.M{"$cond": bson.A{notStarted,
+			bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$periods", bson.A{}}},
+				bson.A{bson.M{"$literal": models.KickOff(change)}},
+			}},
+			"$periods",
+		}}
+	}
+
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"matchKey": key, "status": change.From},
+		mongo.Pipeline{{{Key: "$set", Value: set}}},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Reschedule moves the kick-off of match and records why. With change != nil
+// the status moves too (postponed -> scheduled). Applies only while status and
+// dateTime are what the caller read.
+func (r *MatchRepo) Reschedule(ctx context.Context, key string, match models.Match, rs models.Reschedule, change *models.StatusChange) error {
+	update := bson.M{
+		"$set":  bson.M{"dateTime": rs.To},
+		"$push": bson.M{"reschedules": rs},
+	}
+	if change != nil {
+		if err := CheckTransition(*change); err != nil {
+			return err
+		}
+		update["$set"].(bson.M)["status"] = change.To
+		update["$push"].(bson.M)["transitions"] = change
+	}
+	return r.updateExact(ctx, bson.M{"matchKey": key, "status": match.Status, "dateTime": match.DateTime}, update)
 }
 
-func (r *MatchRepo) ListFinished(ctx context.Context) ([]models.Match, error) {
-	cur, err := r.col.Find(ctx, bson.M{"status": models.Finished})
+// Replay turns an abandoned match back into a fixture on a new date: the played
+// attempt is archived (models.Match.Replayed) and the score starts from 0-0.
+func (r *MatchRepo) Replay(ctx context.Context, key string, match models.Match, change models.StatusChange, rs models.Reschedule) error {
+	if err := CheckTransition(change); err != nil {
+		return err
+	}
+	next := match.Replayed()
+	return r.updateExact(ctx,
+		bson.M{"matchKey": key, "status": change.From},
+		bson.M{
+			"$set": bson.M{
+				"status":    change.To,
+				"dateTime":  rs.To,
+				"homeGoals": 0,
+				"awayGoals": 0,
+				"events":    next.Events,
+				"attempts":  next.Attempts,
+			},
+			"$unset": bson.M{"period": "", "periods": "", "shootout": "", "kickedOffAt": ""},
+			"$push":  bson.M{"transitions": change, "reschedules": rs},
+		},
+	)
+}
+
+// Award records a league-decided result and moves the match to awarded.
+func (r *MatchRepo) Award(ctx context.Context, key string, change models.StatusChange, award models.Award) error {
+	if err := CheckTransition(change); err != nil {
+		return err
+	}
+	return r.updateExact(ctx,
+		bson.M{"matchKey": key, "status": change.From},
+		bson.M{
+			"$set":  bson.M{"status": change.To, "award": award, "awardedAt": change.At},
+			"$push": bson.M{"transitions": change},
+		},
+	)
+}
+
+// FillResult writes an imported result onto a fixture that was never played.
+func (r *MatchRepo) FillResult(ctx context.Context, key string, result models.Match, change models.StatusChange) error {
+	events := result.Events
+	if events == nil {
+		events = []models.MatchEvent{}
+	}
+	return r.updateExact(ctx,
+		bson.M{"matchKey": key, "status": models.Scheduled, "events": bson.M{"$in": bson.A{nil, bson.A{}}}},
+		bson.M{
+			"$set": bson.M{
+				"status":     change.To,
+				"homeGoals":  result.HomeGoals,
+				"awayGoals":  result.AwayGoals,
+				"scoreOnly":  result.ScoreOnly,
+				"events":     events,
+				"finishedAt": change.At,
+			},
+			"$push": bson.M{"transitions": change},
+		},
+	)
+}
+
+// EditFixture sets the edited fields of a fixture in one update.
+func (r *MatchRepo) EditFixture(ctx context.Context, key string, match models.Match, edit models.FixtureEdit) error {
+	set := bson.M{}
+	update := bson.M{"$set": set}
+	if edit.Matchday != nil {
+		set["matchday"] = *edit.Matchday
+	}
+	if edit.Knockout != nil {
+		set["knockout"] = *edit.Knockout
+	}
+	if rs := edit.Reschedule; rs != nil {
+		set["dateTime"] = rs.To
+		update["$push"] = bson.M{"reschedules": *rs}
+	}
+	if len(set) == 0 {
+		delete(update, "$set")
+	}
+	return r.updateExact(ctx, bson.M{"matchKey": key, "status": match.Status, "dateTime": match.DateTime}, update)
+}
+
+// updateExact runs a conditional update; no match means the document moved on (ErrConflict).
+func (r *MatchRepo) updateExact(ctx context.Context, filter bson.M, update any) error {
+	res, err := r.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *MatchRepo) ListByStatus(ctx context.Context, statuses ...models.MatchStatus) ([]models.Match, error) {
+	cur, err := r.col.Find(ctx,
+		bson.M{"status": bson.M{"$in": statuses}},
+		options.Find().SetSort(bson.D{{Key: "dateTime", Value: 1}}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -115,3 +500,39 @@ func (r *MatchRepo) ListFinished(ctx context.Context) ([]models.Match, error) {
 	}
 	return out, cur.Err()
 }
+
+// AssignSeason moves matches without a season into season when both teams take
+// part and the kick-off falls in [from, to]. Used when a season is created for
+// a league that already has matches.
+func (r *MatchRepo) AssignSeason(ctx context.Context, season string, teams []string, from, to time.Time) (int64, error) {
+	res, err := r.col.UpdateMany(ctx,
+		bson.M{
+			"season":   bson.M{"$in": bson.A{nil, ""}},
+			"homeCode": bson.M{"$in": teams},
+			"awayCode": bson.M{"$in": teams},
+			"dateTime": bson.M{"$gte": from, "$lte": to},
+		},
+		bson.M{"$set": bson.M{"season": season}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// seasonFilter restricts f to seasons; "" stands for matches without a season.
+func seasonFilter(f bson.M, seasons []string) bson.M {
+	if len(seasons) == 0 {
+		return f
+	}
+	in := bson.A{}
+	for _, s := range seasons {
+		if s == "" {
+			in = append(in, nil, "")
+			continue
+		}
+		in = append(in, s)
+	}
+	f["season"] = bson.M{"$in": in}
+	return f
+}
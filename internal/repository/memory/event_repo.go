@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"final-by-me/internal/models"
+)
+
+// EventRepo is a thread-safe in-memory implementation of repository.EventStore.
+type EventRepo struct {
+	mu     sync.Mutex
+	events []models.EventLog
+}
+
+func NewEventRepo() *EventRepo {
+	return &EventRepo{}
+}
+
+func (r *EventRepo) Insert(ctx context.Context, e models.EventLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, e)
+	return nil
+}
+
+// All returns a copy of every logged event (oldest first).
+func (r *EventRepo) All() []models.EventLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]models.EventLog{}, r.events...)
+}
@@ -0,0 +1,555 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/standings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MatchRepo is a thread-safe in-memory implementation of repository.MatchStore.
+type MatchRepo struct {
+	mu    sync.RWMutex
+	byKey map[string]*models.Match
+}
+
+func NewMatchRepo() *MatchRepo {
+	return &MatchRepo{byKey: make(map[string]*models.Match)}
+}
+
+func (r *MatchRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *MatchRepo) Create(ctx context.Context, m models.Match) (models.Match, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byKey[m.MatchKey]; ok {
+		return m, errors.New("duplicate matchKey")
+	}
+	if m.ID.IsZero() {
+		m.ID = primitive.NewObjectID()
+	}
+	c := cloneMatch(m)
+	r.byKey[m.MatchKey] = &c
+	return m, nil
+}
+
+func (r *MatchRepo) CreateMany(ctx context.Context, matches []models.Match) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		if _, ok := r.byKey[m.MatchKey]; ok || seen[m.MatchKey] {
+			return 0, repository.ErrConflict
+		}
+		seen[m.MatchKey] = true
+	}
+	for _, m := range matches {
+		if m.ID.IsZero() {
+			m.ID = primitive.NewObjectID()
+		}
+		c := cloneMatch(m)
+		r.byKey[m.MatchKey] = &c
+	}
+	return len(matches), nil
+}
+
+func (r *MatchRepo) List(ctx context.Context, seasons ...string) ([]models.Match, error) {
+	in := seasonSet(seasons)
+	return r.filter(func(m *models.Match) bool { return in(m.Season) }), nil
+}
+
+func (r *MatchRepo) Query(ctx context.Context, q models.MatchQuery) ([]models.Match, *models.MatchCursor, error) {
+	if q.Sort == "" {
+		q.Sort = models.SortDate
+	}
+	list := r.filter(queryMatch(q))
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].ID != list[j].ID && models.CursorOf(list[j], q.Sort).Before(list[i])
+	})
+	if !q.Events {
+		for i := range list {
+			list[i].Events = nil
+		}
+	}
+	if q.Limit <= 0 || len(list) <= q.Limit {
+		return list, nil, nil
+	}
+	list = list[:q.Limit]
+	next := models.CursorOf(list[len(list)-1], q.Sort)
+	return list, &next, nil
+}
+
+// queryMatch selects the matches of q from q.After on.
+func queryMatch(q models.MatchQuery) func(*models.Match) bool {
+	inSeason := seasonSet(q.Seasons)
+	var noSeasonHome map[string]bool
+	if q.NoSeasonHome != nil {
+		noSeasonHome = make(map[string]bool, len(q.NoSeasonHome))
+		for _, c := range q.NoSeasonHome {
+			noSeasonHome[c] = true
+		}
+	}
+	statuses := make(map[models.MatchStatus]bool, len(q.Statuses))
+	for _, s := range q.Statuses {
+		statuses[s] = true
+	}
+
+	return func(m *models.Match) bool {
+		switch {
+		case !inSeason(m.Season):
+			return false
+		case len(q.Seasons) > 0 && m.Season == "" && noSeasonHome != nil && !noSeasonHome[m.HomeCode]:
+			return false
+		case q.Team != "" && m.HomeCode != q.Team && m.AwayCode != q.Team:
+			return false
+		case q.Opponent != "" && m.HomeCode != q.Opponent && m.AwayCode != q.Opponent:
+			return false
+		case len(statuses) > 0 && !statuses[m.Status]:
+			return false
+		case !q.From.IsZero() && m.DateTime.Before(q.From):
+			return false
+		case !q.To.IsZero() && !m.DateTime.Before(q.To):
+			return false
+		case q.Matchday > 0 && m.Matchday != q.Matchday:
+			return false
+		case q.After != nil && q.After.Before(*m):
+			return false
+		}
+		return true
+	}
+}
+
+func (r *MatchRepo) FindByKey(ctx context.Context, key string) (models.Match, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.byKey[key]
+	if !ok {
+		return models.Match{}, false, nil
+	}
+	return cloneMatch(*m), true, nil
+}
+
+func (r *MatchRepo) ExistingKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[string]bool)
+	for _, k := range keys {
+		if _, ok := r.byKey[k]; ok {
+			out[k] = true
+		}
+	}
+	return out, nil
+}
+
+// Universal event insert; the score is recomputed from the events
+func (r *MatchRepo) AddEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || (m.Status != models.Scheduled && m.Status != models.Live) {
+		return nil
+	}
+	m.Events = append(m.Events, e)
+	m.Rescore()
+	return nil
+}
+
+func (r *MatchRepo) UpdateEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent, c *models.EventCorrection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, i, err := r.storedEvent(key, match, e.ID)
+	if err != nil {
+		return err
+	}
+	m.Events[i] = e
+	m.Rescore()
+	if c != nil {
+		m.Corrections = append(m.Corrections, *c)
+	}
+	return nil
+}
+
+func (r *MatchRepo) DeleteEvent(ctx context.Context, key string, match models.Match, id string, c *models.EventCorrection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, i, err := r.storedEvent(key, match, id)
+	if err != nil {
+		return err
+	}
+	m.Events = append(m.Events[:i], m.Events[i+1:]...)
+	m.Rescore()
+	if c != nil {
+		m.Corrections = append(m.Corrections, *c)
+	}
+	return nil
+}
+
+func (r *MatchRepo) RecomputeScore(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	m.Rescore()
+	return nil
+}
+
+// storedEvent finds event id in the stored match and checks it still matches
+// what the caller validated against (same rule as the Mongo conditional update).
+// Must be called with r.mu held.
+func (r *MatchRepo) storedEvent(key string, match models.Match, id string) (*models.Match, int, error) {
+	old, ok := match.FindEvent(id)
+	if !ok {
+		return nil, 0, repository.ErrNotFound
+	}
+	m, ok := r.byKey[key]
+	if !ok || m.Status != match.Status {
+		return nil, 0, repository.ErrConflict
+	}
+	for i, e := range m.Events {
+		if e.ID == id && e.Type == old.Type && e.TeamCode == old.TeamCode && e.GoalType == old.GoalType {
+			return m, i, nil
+		}
+	}
+	return nil, 0, repository.ErrConflict
+}
+
+func (r *MatchRepo) SetPeriod(ctx context.Context, key string, change models.PeriodChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Period != change.From {
+		return repository.ErrConflict
+	}
+	m.Period = change.To
+	m.Periods = append(m.Periods, change)
+	return nil
+}
+
+func (r *MatchRepo) SetShootout(ctx context.Context, key string, s models.Shootout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok {
+		return repository.ErrConflict
+	}
+	stored := 0
+	if m.Shootout != nil {
+		stored = len(m.Shootout.Kicks)
+	}
+	if stored != len(s.Kicks)-1 {
+		return repository.ErrConflict
+	}
+	s.Kicks = append([]models.ShootoutKick{}, s.Kicks...)
+	m.Shootout = &s
+	return nil
+}
+
+func (r *MatchRepo) SetStatus(ctx context.Context, key string, change models.StatusChange) error {
+	if err := repository.CheckTransition(change); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != change.From {
+		return repository.ErrConflict
+	}
+	m.Stamp(change)
+	return nil
+}
+
+func (r *MatchRepo) Reschedule(ctx context.Context, key string, match models.Match, rs models.Reschedule, change *models.StatusChange) error {
+	if change != nil {
+		if err := repository.CheckTransition(*change); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != match.Status || !m.DateTime.Equal(match.DateTime) {
+		return repository.ErrConflict
+	}
+	m.DateTime = rs.To
+	m.Reschedules = append(m.Reschedules, rs)
+	if change != nil {
+		m.Status = change.To
+		m.Transitions = append(m.Transitions, *change)
+	}
+	return nil
+}
+
+func (r *MatchRepo) Replay(ctx context.Context, key string, match models.Match, change models.StatusChange, rs models.Reschedule) error {
+	if err := repository.CheckTransition(change); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != change.From {
+		return repository.ErrConflict
+	}
+	next := m.Replayed()
+	next.Status = change.To
+	next.DateTime = rs.To
+	next.Transitions = append(next.Transitions, change)
+	next.Reschedules = append(next.Reschedules, rs)
+	*m = next
+	return nil
+}
+
+func (r *MatchRepo) Award(ctx context.Context, key string, change models.StatusChange, award models.Award) error {
+	if err := repository.CheckTransition(change); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != change.From {
+		return repository.ErrConflict
+	}
+	m.Award = &award
+	m.Stamp(change)
+	return nil
+}
+
+func (r *MatchRepo) FillResult(ctx context.Context, key string, result models.Match, change models.StatusChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != models.Scheduled || len(m.Events) > 0 {
+		return repository.ErrConflict
+	}
+	m.Events = append([]models.MatchEvent{}, result.Events...)
+	m.HomeGoals, m.AwayGoals = result.HomeGoals, result.AwayGoals
+	m.ScoreOnly = result.ScoreOnly
+	m.Stamp(change)
+	return nil
+}
+
+func (r *MatchRepo) EditFixture(ctx context.Context, key string, match models.Match, edit models.FixtureEdit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.byKey[key]
+	if !ok || m.Status != match.Status || !m.DateTime.Equal(match.DateTime) {
+		return repository.ErrConflict
+	}
+	if edit.Matchday != nil {
+		m.Matchday = *edit.Matchday
+	}
+	if edit.Knockout != nil {
+		m.Knockout = *edit.Knockout
+	}
+	if rs := edit.Reschedule; rs != nil {
+		m.DateTime = rs.To
+		m.Reschedules = append(m.Reschedules, *rs)
+	}
+	return nil
+}
+
+// ResultLines returns the table lines of matches that have a result: finished or awarded.
+func (r *MatchRepo) ResultLines(ctx context.Context, seasons ...string) ([]models.ResultLine, error) {
+	in := seasonSet(seasons)
+	return standings.Lines(r.filter(func(m *models.Match) bool { return m.Status.HasResult() && in(m.Season) })), nil
+}
+
+func (r *MatchRepo) Stats(ctx context.Context, seasons ...string) (models.MatchStats, error) {
+	in := seasonSet(seasons)
+	st := models.MatchStats{ByStatus: map[models.MatchStatus]int{}, GoalsByType: map[string]int{}}
+	for _, m := range r.filter(func(m *models.Match) bool { return in(m.Season) }) {
+		st.Total++
+		st.ByStatus[m.Status]++
+		if m.Status != models.Finished {
+			continue
+		}
+		st.Played++
+		st.Goals += m.HomeGoals + m.AwayGoals
+		for _, e := range m.Events {
+			switch e.Type {
+			case models.EventGoal:
+				t := e.GoalType
+				if t == "" {
+					t = models.GoalOpenPlay
+				}
+				st.GoalsByType[t]++
+			case models.EventPenaltyMissed:
+				st.PenaltiesMissed++
+			}
+		}
+	}
+	return st, nil
+}
+
+func (r *MatchRepo) PlayerTotals(ctx context.Context, q models.MatchQuery) ([]models.PlayerTotal, error) {
+	list := r.filter(queryMatch(q))
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].ID != list[j].ID && models.CursorOf(list[j], models.SortDate).Before(list[i])
+	})
+
+	byKey := map[string]*models.PlayerTotal{}
+	var order []string
+	credit := func(id, name, team string) *models.PlayerTotal {
+		key := models.PlayerKey(id, name, team)
+		t, ok := byKey[key]
+		if !ok {
+			t = &models.PlayerTotal{PlayerID: id}
+			byKey[key] = t
+			order = append(order, key)
+		}
+		if name != "" {
+			t.Player = name
+		}
+		t.TeamCode = team
+		return t
+	}
+	for _, m := range list {
+		for _, a := range m.Appearances() {
+			t := credit(a.PlayerID, a.Player, a.TeamCode)
+			t.Matches++
+			t.Minutes += a.Minutes
+		}
+		for _, e := range m.Events {
+			if e.Type != models.EventGoal || e.GoalType == models.GoalOwnGoal {
+				continue
+			}
+			if e.PlayerID != "" || e.Player != "" {
+				t := credit(e.PlayerID, e.Player, e.TeamCode)
+				t.Goals++
+				if e.GoalType == models.GoalPenalty {
+					t.Penalties++
+				}
+			}
+			if e.AssistID != "" || e.Assist != "" {
+				credit(e.AssistID, e.Assist, e.TeamCode).Assists++
+			}
+		}
+	}
+
+	out := make([]models.PlayerTotal, 0, len(order))
+	for _, key := range order {
+		out = append(out, *byKey[key])
+	}
+	return out, nil
+}
+
+func (r *MatchRepo) ListByStatus(ctx context.Context, statuses ...models.MatchStatus) ([]models.Match, error) {
+	return r.filter(func(m *models.Match) bool {
+		for _, s := range statuses {
+			if m.Status == s {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *MatchRepo) AssignSeason(ctx context.Context, season string, teams []string, from, to time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	in := make(map[string]bool, len(teams))
+	for _, c := range teams {
+		in[c] = true
+	}
+	var n int64
+	for _, m := range r.byKey {
+		if m.Season != "" || !in[m.HomeCode] || !in[m.AwayCode] || m.DateTime.Before(from) || m.DateTime.After(to) {
+			continue
+		}
+		m.Season = season
+		n++
+	}
+	return n, nil
+}
+
+// seasonSet matches the seasons filter of the Mongo repo: no seasons means any.
+func seasonSet(seasons []string) func(string) bool {
+	if len(seasons) == 0 {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]bool, len(seasons))
+	for _, s := range seasons {
+		set[s] = true
+	}
+	return func(s string) bool { return set[s] }
+}
+
+// filter returns copies of matching matches sorted by dateTime
+func (r *MatchRepo) filter(keep func(*models.Match) bool) []models.Match {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []models.Match
+	for _, m := range r.byKey {
+		if keep(m) {
+			out = append(out, cloneMatch(*m))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].DateTime.Equal(out[j].DateTime) {
+			return out[i].DateTime.Before(out[j].DateTime)
+		}
+		return out[i].MatchKey < out[j].MatchKey
+	})
+	return out
+}
+
+func cloneMatch(m models.Match) models.Match {
+	if m.Events != nil {
+		m.Events = append([]models.MatchEvent{}, m.Events...)
+	}
+	if m.Periods != nil {
+		m.Periods = append([]models.PeriodChange{}, m.Periods...)
+	}
+	if m.Transitions != nil {
+		m.Transitions = append([]models.StatusChange{}, m.Transitions...)
+	}
+	if m.Reschedules != nil {
+		m.Reschedules = append([]models.Reschedule{}, m.Reschedules...)
+	}
+	if m.Attempts != nil {
+		m.Attempts = append([]models.Attempt{}, m.Attempts...)
+	}
+	if m.Award != nil {
+		a := *m.Award
+		m.Award = &a
+	}
+	if m.Shootout != nil {
+		s := *m.Shootout
+		s.Kicks = append([]models.ShootoutKick{}, s.Kicks...)
+		m.Shootout = &s
+	}
+	return m
+}
@@ -0,0 +1,16 @@
+// Package memory is an in-memory storage backend (STORAGE=memory).
+// Data lives only as long as the process; useful for local runs and tests without MongoDB.
+package memory
+
+import "final-by-me/internal/repository"
+
+var (
+	_ repository.MatchStore     = (*MatchRepo)(nil)
+	_ repository.TeamStore      = (*TeamRepo)(nil)
+	_ repository.SeasonStore    = (*SeasonRepo)(nil)
+	_ repository.PlayerStore    = (*PlayerRepo)(nil)
+	_ repository.RulesStore     = (*RulesRepo)(nil)
+	_ repository.StandingsStore = (*StandingsRepo)(nil)
+	_ repository.EventStore     = (*EventRepo)(nil)
+	_ repository.UserStore      = (*UserRepo)(nil)
+)
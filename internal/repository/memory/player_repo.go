@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// PlayerRepo is a thread-safe in-memory implementation of repository.PlayerStore.
+type PlayerRepo struct {
+	mu   sync.RWMutex
+	byID map[string]models.Player
+}
+
+func NewPlayerRepo() *PlayerRepo {
+	return &PlayerRepo{byID: make(map[string]models.Player)}
+}
+
+func (r *PlayerRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *PlayerRepo) Create(ctx context.Context, p models.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[p.ID]; ok || r.numberTaken(p) {
+		return repository.ErrConflict
+	}
+	r.byID[p.ID] = p
+	return nil
+}
+
+func (r *PlayerRepo) Find(ctx context.Context, id string) (models.Player, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.byID[id]
+	return p, ok, nil
+}
+
+func (r *PlayerRepo) ListByTeam(ctx context.Context, team string) ([]models.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []models.Player
+	for _, p := range r.byID {
+		if p.TeamCode == team {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Number != out[j].Number {
+			return out[i].Number < out[j].Number
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func (r *PlayerRepo) HasSquad(ctx context.Context, team string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.byID {
+		if p.TeamCode == team {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *PlayerRepo) Update(ctx context.Context, p models.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[p.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if r.numberTaken(p) {
+		return repository.ErrConflict
+	}
+	r.byID[p.ID] = p
+	return nil
+}
+
+// numberTaken reports whether another player of p's team wears p's number.
+func (r *PlayerRepo) numberTaken(p models.Player) bool {
+	if p.TeamCode == "" || p.Number == 0 {
+		return false
+	}
+	for _, o := range r.byID {
+		if o.ID != p.ID && o.TeamCode == p.TeamCode && o.Number == p.Number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"final-by-me/internal/models"
+)
+
+// RulesRepo is a thread-safe in-memory implementation of repository.RulesStore.
+type RulesRepo struct {
+	mu       sync.RWMutex
+	byLeague map[string]models.LeagueRules
+}
+
+func NewRulesRepo() *RulesRepo {
+	return &RulesRepo{byLeague: make(map[string]models.LeagueRules)}
+}
+
+func (r *RulesRepo) SeedIfEmpty(ctx context.Context, rules []models.LeagueRules) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.byLeague) > 0 {
+		return nil
+	}
+	for _, lr := range rules {
+		r.byLeague[lr.League] = cloneRules(lr)
+	}
+	return nil
+}
+
+func (r *RulesRepo) Find(ctx context.Context, league string) (models.LeagueRules, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lr, ok := r.byLeague[league]
+	if !ok {
+		return models.LeagueRules{}, false, nil
+	}
+	return cloneRules(lr), true, nil
+}
+
+func (r *RulesRepo) Upsert(ctx context.Context, rules models.LeagueRules) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byLeague[rules.League] = cloneRules(rules)
+	return nil
+}
+
+func cloneRules(lr models.LeagueRules) models.LeagueRules {
+	lr.TieBreakers = append([]models.TieBreaker{}, lr.TieBreakers...)
+	if lr.Bonuses != nil {
+		lr.Bonuses = append([]models.Bonus{}, lr.Bonuses...)
+	}
+	return lr
+}
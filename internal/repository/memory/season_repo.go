@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// SeasonRepo is a thread-safe in-memory implementation of repository.SeasonStore.
+type SeasonRepo struct {
+	mu   sync.RWMutex
+	byID map[string]models.Season
+}
+
+func NewSeasonRepo() *SeasonRepo {
+	return &SeasonRepo{byID: make(map[string]models.Season)}
+}
+
+func (r *SeasonRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *SeasonRepo) Create(ctx context.Context, s models.Season) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[s.ID]; ok {
+		return repository.ErrConflict
+	}
+	r.byID[s.ID] = cloneSeason(s)
+	return nil
+}
+
+func (r *SeasonRepo) List(ctx context.Context, league string) ([]models.Season, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []models.Season
+	for _, s := range r.byID {
+		if league == "" || s.League == league {
+			out = append(out, cloneSeason(s))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].League != out[j].League {
+			return out[i].League < out[j].League
+		}
+		return out[i].StartDate.Before(out[j].StartDate)
+	})
+	return out, nil
+}
+
+func (r *SeasonRepo) Find(ctx context.Context, id string) (models.Season, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.byID[id]
+	if !ok {
+		return models.Season{}, false, nil
+	}
+	return cloneSeason(s), true, nil
+}
+
+func (r *SeasonRepo) Close(ctx context.Context, id string, at time.Time, by string, table []models.TableRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.byID[id]
+	if !ok || s.Status != models.SeasonOpen {
+		return repository.ErrConflict
+	}
+	s.Status = models.SeasonClosed
+	s.ClosedAt = &at
+	s.ClosedBy = by
+	s.FinalTable = append([]models.TableRow{}, table...)
+	r.byID[id] = s
+	return nil
+}
+
+func cloneSeason(s models.Season) models.Season {
+	s.Teams = append([]string{}, s.Teams...)
+	if s.FinalTable != nil {
+		s.FinalTable = append([]models.TableRow{}, s.FinalTable...)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// StandingsRepo is a thread-safe in-memory implementation of repository.StandingsStore.
+type StandingsRepo struct {
+	mu   sync.RWMutex
+	byID map[string]models.Standings
+}
+
+func NewStandingsRepo() *StandingsRepo {
+	return &StandingsRepo{byID: make(map[string]models.Standings)}
+}
+
+func (r *StandingsRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *StandingsRepo) List(ctx context.Context, league string, seasons ...string) ([]models.Standings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	in := seasonSet(seasons)
+	var out []models.Standings
+	for _, s := range r.byID {
+		if (league == "" || s.League == league) && in(s.Season) {
+			out = append(out, cloneStandings(s))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *StandingsRepo) Find(ctx context.Context, id string) (models.Standings, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.byID[id]
+	if !ok {
+		return models.Standings{}, false, nil
+	}
+	return cloneStandings(s), true, nil
+}
+
+func (r *StandingsRepo) Save(ctx context.Context, s models.Standings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.byID[s.ID]
+	switch {
+	case s.Version <= 1 && ok:
+		return repository.ErrConflict
+	case s.Version > 1 && (!ok || old.Version != s.Version-1):
+		return repository.ErrConflict
+	}
+	r.byID[s.ID] = cloneStandings(s)
+	return nil
+}
+
+func cloneStandings(s models.Standings) models.Standings {
+	s.Teams = append([]models.Team{}, s.Teams...)
+	s.Lines = append([]models.ResultLine{}, s.Lines...)
+	rows := make([]models.TableRow, len(s.Rows))
+	for i, row := range s.Rows {
+		row.Form = append([]models.FormResult{}, row.Form...)
+		rows[i] = row
+	}
+	s.Rows = rows
+	return s
+}
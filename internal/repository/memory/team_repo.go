@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// TeamRepo is a thread-safe in-memory implementation of repository.TeamStore.
+type TeamRepo struct {
+	mu     sync.RWMutex
+	byCode map[string]models.Team
+}
+
+func NewTeamRepo() *TeamRepo {
+	return &TeamRepo{byCode: make(map[string]models.Team)}
+}
+
+func (r *TeamRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *TeamRepo) SeedIfEmpty(ctx context.Context, teams []models.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.byCode) > 0 {
+		return nil
+	}
+	for _, t := range teams {
+		r.byCode[t.Code] = t
+	}
+	return nil
+}
+
+func (r *TeamRepo) List(ctx context.Context) ([]models.Team, error) {
+	out := r.filter(func(models.Team) bool { return true })
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].League != out[j].League {
+			return out[i].League < out[j].League
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func (r *TeamRepo) ListByLeague(ctx context.Context, league string) ([]models.Team, error) {
+	out := r.filter(func(t models.Team) bool { return t.League == league })
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (r *TeamRepo) Exists(ctx context.Context, code string) (bool, error) {
+	_, ok, err := r.Find(ctx, code)
+	return ok, err
+}
+
+func (r *TeamRepo) Find(ctx context.Context, code string) (models.Team, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.byCode[code]
+	return t, ok, nil
+}
+
+func (r *TeamRepo) Update(ctx context.Context, t models.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byCode[t.Code]; !ok {
+		return repository.ErrNotFound
+	}
+	r.byCode[t.Code] = t
+	return nil
+}
+
+func (r *TeamRepo) filter(keep func(models.Team) bool) []models.Team {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []models.Team
+	for _, t := range r.byCode {
+		if keep(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepo is a thread-safe in-memory implementation of repository.UserStore.
+type UserRepo struct {
+	mu      sync.RWMutex
+	byEmail map[string]models.User
+}
+
+func NewUserRepo() *UserRepo {
+	return &UserRepo{byEmail: make(map[string]models.User)}
+}
+
+func (r *UserRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *UserRepo) EmailExists(ctx context.Context, email string) (bool, error) {
+	_, ok, err := r.FindByEmail(ctx, email)
+	return ok, err
+}
+
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (models.User, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.byEmail[email]
+	return u, ok, nil
+}
+
+func (r *UserRepo) Create(ctx context.Context, u models.User) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byEmail[u.Email]; ok {
+		return u, errors.New("duplicate email")
+	}
+	if u.ID.IsZero() {
+		u.ID = primitive.NewObjectID()
+	}
+	r.byEmail[u.Email] = u
+	return u, nil
+}
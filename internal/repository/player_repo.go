@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PlayerRepo struct {
+	col *mongo.Collection
+}
+
+func NewPlayerRepo(db *mongo.Database) *PlayerRepo {
+	return &PlayerRepo{col: db.Collection("players")}
+}
+
+func (r *PlayerRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "teamCode", Value: 1}, {Key: "name", Value: 1}}},
+		// one shirt number per squad; players without a number or a team are left out
+		{
+			Keys: bson.D{{Key: "teamCode", Value: 1}, {Key: "number", Value: 1}},
+			Options: options.Index().SetName("teamCode_number_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"teamCode": bson.M{"$gt": ""}, "number": bson.M{"$gt": 0}}),
+		},
+	})
+	return err
+}
+
+func (r *PlayerRepo) Create(ctx context.Context, p models.Player) error {
+	_, err := r.col.InsertOne(ctx, p)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *PlayerRepo) Find(ctx context.Context, id string) (models.Player, bool, error) {
+	var p models.Player
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return models.Player{}, false, nil
+	}
+	if err != nil {
+		return models.Player{}, false, err
+	}
+	return p, true, nil
+}
+
+func (r *PlayerRepo) ListByTeam(ctx context.Context, team string) ([]models.Player, error) {
+	cur, err := r.col.Find(ctx, bson.M{"teamCode": team},
+		options.Find().SetSort(bson.D{{Key: "number", Value: 1}, {Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.Player
+	for cur.Next(ctx) {
+		var p models.Player
+		if err := cur.Decode(&p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, cur.Err()
+}
+
+func (r *PlayerRepo) HasSquad(ctx context.Context, team string) (bool, error) {
+	n, err := r.col.CountDocuments(ctx, bson.M{"teamCode": team}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *PlayerRepo) Update(ctx context.Context, p models.Player) error {
+	res, err := r.col.ReplaceOne(ctx, bson.M{"_id": p.ID}, p)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
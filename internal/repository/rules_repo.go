@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RulesRepo stores one LeagueRules document per league (_id = league).
+type RulesRepo struct {
+	col *mongo.Collection
+}
+
+func NewRulesRepo(db *mongo.Database) *RulesRepo {
+	return &RulesRepo{col: db.Collection("league_rules")}
+}
+
+func (r *RulesRepo) SeedIfEmpty(ctx context.Context, rules []models.LeagueRules) error {
+	count, err := r.col.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	docs := make([]any, 0, len(rules))
+	for _, lr := range rules {
+		docs = append(docs, lr)
+	}
+	_, err = r.col.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *RulesRepo) Find(ctx context.Context, league string) (models.LeagueRules, bool, error) {
+	var lr models.LeagueRules
+	err := r.col.FindOne(ctx, bson.M{"_id": league}).Decode(&lr)
+	if err == mongo.ErrNoDocuments {
+		return models.LeagueRules{}, false, nil
+	}
+	if err != nil {
+		return models.LeagueRules{}, false, err
+	}
+	return lr, true, nil
+}
+
+func (r *RulesRepo) Upsert(ctx context.Context, rules models.LeagueRules) error {
+	_, err := r.col.ReplaceOne(ctx, bson.M{"_id": rules.League}, rules, options.Replace().SetUpsert(true))
+	return err
+}
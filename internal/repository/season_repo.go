@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SeasonRepo struct {
+	col *mongo.Collection
+}
+
+func NewSeasonRepo(db *mongo.Database) *SeasonRepo {
+	return &SeasonRepo{col: db.Collection("seasons")}
+}
+
+func (r *SeasonRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "league", Value: 1}, {Key: "startDate", Value: 1}},
+	})
+	return err
+}
+
+func (r *SeasonRepo) Create(ctx context.Context, s models.Season) error {
+	_, err := r.col.InsertOne(ctx, s)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *SeasonRepo) List(ctx context.Context, league string) ([]models.Season, error) {
+	filter := bson.M{}
+	if league != "" {
+		filter["league"] = league
+	}
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "league", Value: 1}, {Key: "startDate", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.Season
+	for cur.Next(ctx) {
+		var s models.Season
+		if err := cur.Decode(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, cur.Err()
+}
+
+func (r *SeasonRepo) Find(ctx context.Context, id string) (models.Season, bool, error) {
+	var s models.Season
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return models.Season{}, false, nil
+	}
+	if err != nil {
+		return models.Season{}, false, err
+	}
+	return s, true, nil
+}
+
+func (r *SeasonRepo) Close(ctx context.Context, id string, at time.Time, by string, table []models.TableRow) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.SeasonOpen},
+		bson.M{"$set": bson.M{
+			"status":     models.SeasonClosed,
+			"closedAt":   at,
+			"closedBy":   by,
+			"finalTable": table,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StandingsRepo stores the standings projection, one document per league
+// season (_id = models.StandingsID).
+type StandingsRepo struct {
+	col *mongo.Collection
+}
+
+func NewStandingsRepo(db *mongo.Database) *StandingsRepo {
+	return &StandingsRepo{col: db.Collection("standings")}
+}
+
+func (r *StandingsRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "league", Value: 1}, {Key: "season", Value: 1}},
+	})
+	return err
+}
+
+func (r *StandingsRepo) List(ctx context.Context, league string, seasons ...string) ([]models.Standings, error) {
+	filter := bson.M{}
+	if league != "" {
+		filter["league"] = league
+	}
+	if len(seasons) > 0 {
+		filter["season"] = bson.M{"$in": seasons}
+	}
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.Standings
+	for cur.Next(ctx) {
+		var s models.Standings
+		if err := cur.Decode(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, cur.Err()
+}
+
+func (r *StandingsRepo) Find(ctx context.Context, id string) (models.Standings, bool, error) {
+	var s models.Standings
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return models.Standings{}, false, nil
+	}
+	if err != nil {
+		return models.Standings{}, false, err
+	}
+	return s, true, nil
+}
+
+func (r *StandingsRepo) Save(ctx context.Context, s models.Standings) error {
+	if s.Version <= 1 {
+		_, err := r.col.InsertOne(ctx, s)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	res, err := r.col.ReplaceOne(ctx, bson.M{"_id": s.ID, "version": s.Version - 1}, s)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrConflict: the document changed between read and conditional update
+	ErrConflict = errors.New("conflict")
+)
+
+// TransitionError is returned for a status change the lifecycle does not allow.
+type TransitionError struct {
+	From    models.MatchStatus
+	To      models.MatchStatus
+	Allowed []models.MatchStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal status transition %s -> %s", e.From, e.To)
+}
+
+// CheckTransition is shared by every MatchStore implementation.
+func CheckTransition(c models.StatusChange) error {
+	if !c.From.CanMoveTo(c.To) {
+		return &TransitionError{From: c.From, To: c.To, Allowed: c.From.NextStatuses()}
+	}
+	return nil
+}
+
+// Storage interfaces used by handlers and workers.
+// Implemented by the Mongo repos in this package and by package memory.
+
+type MatchStore interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, m models.Match) (models.Match, error)
+	// CreateMany inserts matches in bulk. If any matchKey is already taken
+	// nothing is inserted and ErrConflict is returned.
+	CreateMany(ctx context.Context, matches []models.Match) (int, error)
+	// List reads every match, or only those of the given seasons ("" stands
+	// for matches without a season).
+	List(ctx context.Context, seasons ...string) ([]models.Match, error)
+	// Query lists one page of the matches selected by q; next is the cursor of
+	// the following page, nil on the last one.
+	Query(ctx context.Context, q models.MatchQuery) (page []models.Match, next *models.MatchCursor, err error)
+	FindByKey(ctx context.Context, key string) (models.Match, bool, error)
+	// ExistingKeys reports which of keys are taken by a stored match.
+	ExistingKeys(ctx context.Context, keys []string) (map[string]bool, error)
+	AddEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent) error
+	// UpdateEvent and DeleteEvent return ErrConflict when the event or the
+	// status of match changed meanwhile; a non-nil c is appended to Corrections.
+	UpdateEvent(ctx context.Context, key string, match models.Match, e models.MatchEvent, c *models.EventCorrection) error
+	DeleteEvent(ctx context.Context, key string, match models.Match, id string, c *models.EventCorrection) error
+	RecomputeScore(ctx context.Context, key string) error
+	SetPeriod(ctx context.Context, key string, change models.PeriodChange) error
+	SetShootout(ctx context.Context, key string, s models.Shootout) error
+	// SetStatus applies change if it is a legal transition and the match is still in change.From.
+	SetStatus(ctx context.Context, key string, change models.StatusChange) error
+	Reschedule(ctx context.Context, key string, match models.Match, rs models.Reschedule, change *models.StatusChange) error
+	Replay(ctx context.Context, key string, match models.Match, change models.StatusChange, rs models.Reschedule) error
+	Award(ctx context.Context, key string, change models.StatusChange, award models.Award) error
+	// FillResult gives a scheduled match without events the score, events and
+	// ScoreOnly flag of result, finished by change (an import, not a lifecycle
+	// move). ErrConflict once the match is no longer scheduled or has events.
+	FillResult(ctx context.Context, key string, result models.Match, change models.StatusChange) error
+	// EditFixture applies edit while status and dateTime are what match has,
+	// otherwise ErrConflict.
+	EditFixture(ctx context.Context, key string, match models.Match, edit models.FixtureEdit) error
+	// ResultLines returns the table lines of matches with a result (finished
+	// or awarded), optionally only those of the given seasons, oldest first.
+	ResultLines(ctx context.Context, seasons ...string) ([]models.ResultLine, error)
+	// Stats aggregates the matches of the given seasons (all when none).
+	Stats(ctx context.Context, seasons ...string) (models.MatchStats, error)
+	// PlayerTotals sums the appearances, goals and assists of the players
+	// named in the events of the matches q selects, oldest first; sort and
+	// paging are ignored.
+	PlayerTotals(ctx context.Context, q models.MatchQuery) ([]models.PlayerTotal, error)
+	AssignSeason(ctx context.Context, season string, teams []string, from, to time.Time) (int64, error)
+	ListByStatus(ctx context.Context, statuses ...models.MatchStatus) ([]models.Match, error)
+}
+
+type TeamStore interface {
+	EnsureIndexes(ctx context.Context) error
+	SeedIfEmpty(ctx context.Context, teams []models.Team) error
+	List(ctx context.Context) ([]models.Team, error)
+	ListByLeague(ctx context.Context, league string) ([]models.Team, error)
+	Exists(ctx context.Context, code string) (bool, error)
+	Find(ctx context.Context, code string) (models.Team, bool, error)
+	// Update replaces the stored team; ErrNotFound if there is none.
+	Update(ctx context.Context, t models.Team) error
+}
+
+type PlayerStore interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create and Update return ErrConflict when the shirt number is taken in the team.
+	Create(ctx context.Context, p models.Player) error
+	Find(ctx context.Context, id string) (models.Player, bool, error)
+	// ListByTeam lists the squad of team, by shirt number.
+	ListByTeam(ctx context.Context, team string) ([]models.Player, error)
+	// HasSquad reports whether any player is registered to team.
+	HasSquad(ctx context.Context, team string) (bool, error)
+	Update(ctx context.Context, p models.Player) error
+}
+
+type SeasonStore interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, s models.Season) error
+	// List returns the seasons of league ("" for all), oldest first.
+	List(ctx context.Context, league string) ([]models.Season, error)
+	Find(ctx context.Context, id string) (models.Season, bool, error)
+	// Close archives table and marks the season closed; ErrConflict if it already is.
+	Close(ctx context.Context, id string, at time.Time, by string, table []models.TableRow) error
+}
+
+type RulesStore interface {
+	SeedIfEmpty(ctx context.Context, rules []models.LeagueRules) error
+	Find(ctx context.Context, league string) (models.LeagueRules, bool, error)
+	Upsert(ctx context.Context, rules models.LeagueRules) error
+}
+
+// StandingsStore keeps one models.Standings per league season.
+type StandingsStore interface {
+	EnsureIndexes(ctx context.Context) error
+	// List returns the standings of league ("" for all) and, when given, only
+	// those of seasons ("" stands for matches without a season).
+	List(ctx context.Context, league string, seasons ...string) ([]models.Standings, error)
+	Find(ctx context.Context, id string) (models.Standings, bool, error)
+	// Save writes s if the stored version is still s.Version-1 (version 1
+	// creates it); ErrConflict otherwise.
+	Save(ctx context.Context, s models.Standings) error
+}
+
+type EventStore interface {
+	Insert(ctx context.Context, e models.EventLog) error
+}
+
+type UserStore interface {
+	EnsureIndexes(ctx context.Context) error
+	EmailExists(ctx context.Context, email string) (bool, error)
+	FindByEmail(ctx context.Context, email string) (models.User, bool, error)
+	Create(ctx context.Context, u models.User) (models.User, error)
+}
+
+var (
+	_ MatchStore     = (*MatchRepo)(nil)
+	_ TeamStore      = (*TeamRepo)(nil)
+	_ SeasonStore    = (*SeasonRepo)(nil)
+	_ PlayerStore    = (*PlayerRepo)(nil)
+	_ RulesStore     = (*RulesRepo)(nil)
+	_ StandingsStore = (*StandingsRepo)(nil)
+	_ EventStore     = (*EventRepo)(nil)
+	_ UserStore      = (*UserRepo)(nil)
+)
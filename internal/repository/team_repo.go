@@ -91,3 +91,14 @@ func (r *TeamRepo) Find(ctx context.Context, code string) (models.Team, bool, er
 	}
 	return t, true, nil
 }
+
+func (r *TeamRepo) Update(ctx context.Context, t models.Team) error {
+	res, err := r.col.ReplaceOne(ctx, bson.M{"_id": t.Code}, t)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
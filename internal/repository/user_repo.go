@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UserRepo struct {
+	col *mongo.Collection
+}
+
+func NewUserRepo(db *mongo.Database) *UserRepo {
+	return &UserRepo{col: db.Collection("user")}
+}
+
+func (r *UserRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *UserRepo) EmailExists(ctx context.Context, email string) (bool, error) {
+	c, err := r.col.CountDocuments(ctx, bson.M{"email": email})
+	return c > 0, err
+}
+
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (models.User, bool, error) {
+	var u models.User
+	err := r.col.FindOne(ctx, bson.M{"email": email}).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return models.User{}, false, nil
+	}
+	if err != nil {
+		return models.User{}, false, err
+	}
+	return u, true, nil
+}
+
+func (r *UserRepo) Create(ctx context.Context, u models.User) (models.User, error) {
+	if u.ID.IsZero() {
+		u.ID = primitive.NewObjectID()
+	}
+	_, err := r.col.InsertOne(ctx, u)
+	return u, err
+}
@@ -0,0 +1,99 @@
+// Package scores checks stored match scores against their goal events.
+package scores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+type Score struct {
+	Home int `json:"home"`
+	Away int `json:"away"`
+}
+
+type Mismatch struct {
+	MatchKey   string             `json:"matchKey"`
+	Status     models.MatchStatus `json:"status"`
+	Stored     Score              `json:"stored"`
+	FromEvents Score              `json:"fromEvents"`
+	Repaired   bool               `json:"repaired"`
+	Error      string             `json:"error,omitempty"`
+}
+
+type Report struct {
+	Scanned    int        `json:"scanned"`
+	Mismatches []Mismatch `json:"mismatches"`
+	Repair     bool       `json:"repair"`
+}
+
+// RepairedKeys lists the matches whose score was rewritten.
+func (r Report) RepairedKeys() []string {
+	var out []string
+	for _, mm := range r.Mismatches {
+		if mm.Repaired {
+			out = append(out, mm.MatchKey)
+		}
+	}
+	return out
+}
+
+// Reconcile scans every match and reports where homeGoals/awayGoals disagree
+// with the goal events; score-only imports have none to compare with. Each
+// mismatch is written to the events log, and a failed write is reported in
+// its Error; with repair=true the stored score is rewritten from the events.
+func Reconcile(ctx context.Context, matches repository.MatchStore, log repository.EventStore, repair bool) (Report, error) {
+	all, err := matches.List(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	rep := Report{Scanned: len(all), Mismatches: []Mismatch{}, Repair: repair}
+	for _, m := range all {
+		if m.ScoreOnly {
+			continue
+		}
+		h, a := m.ScoreFromEvents()
+		if h == m.HomeGoals && a == m.AwayGoals {
+			continue
+		}
+
+		mm := Mismatch{
+			MatchKey:   m.MatchKey,
+			Status:     m.Status,
+			Stored:     Score{Home: m.HomeGoals, Away: m.AwayGoals},
+			FromEvents: Score{Home: h, Away: a},
+		}
+		if repair {
+			if err := matches.RecomputeScore(ctx, m.MatchKey); err != nil {
+				mm.Error = err.Error()
+			} else {
+				mm.Repaired = true
+			}
+		}
+
+		typ := "score_mismatch"
+		if mm.Repaired {
+			typ = "score_repaired"
+		}
+		if log != nil {
+			err := log.Insert(ctx, models.EventLog{
+				Type:      typ,
+				Message:   fmt.Sprintf("stored %d-%d, events say %d-%d", m.HomeGoals, m.AwayGoals, h, a),
+				MatchKey:  m.MatchKey,
+				CreatedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				if mm.Error != "" {
+					mm.Error += "; "
+				}
+				mm.Error += "events log: " + err.Error()
+			}
+		}
+		rep.Mismatches = append(rep.Mismatches, mm)
+	}
+	return rep, nil
+}
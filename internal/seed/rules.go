@@ -0,0 +1,26 @@
+package seed
+
+import "final-by-me/internal/models"
+
+// Rules are the starting competition rules per league; admins change them
+// through PUT /leagues/{league}/rules.
+func Rules() []models.LeagueRules {
+	return []models.LeagueRules{
+		{
+			League: "EPL", PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []models.TieBreaker{models.TieGoalDifference, models.TieGoalsFor, models.TieHeadToHeadPoints, models.TieAwayGoals},
+		},
+		{
+			League: "LaLiga", PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []models.TieBreaker{models.TieHeadToHeadPoints, models.TieHeadToHeadGD, models.TieGoalDifference, models.TieGoalsFor, models.TieFairPlay},
+		},
+		{
+			League: "SerieA", PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []models.TieBreaker{models.TieHeadToHeadPoints, models.TieHeadToHeadGD, models.TieGoalDifference, models.TieGoalsFor},
+		},
+		{
+			League: "KPL", PointsWin: 3, PointsDraw: 1,
+			TieBreakers: []models.TieBreaker{models.TieWins, models.TieHeadToHeadPoints, models.TieHeadToHeadGD, models.TieGoalDifference, models.TieGoalsFor},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+// Package squad checks the players named in match events against the
+// player registry.
+package squad
+
+import (
+	"context"
+
+	"final-by-me/internal/models"
+	"final-by-me/internal/repository"
+)
+
+// ResolveEvent checks the player ids of e: each must be registered to
+// e.TeamCode, and its registered name replaces the free-text one. When e
+// replaces old, an id old already had for the same team is kept without the
+// check, so events stay editable after a transfer or release. Players named
+// only by free text are accepted when allowText is set (legacy data) or when
+// e.TeamCode has no registered squad yet. msg explains a rejection.
+func ResolveEvent(ctx context.Context, players repository.PlayerStore, e *models.MatchEvent, old *models.MatchEvent, allowText bool) (msg string, err error) {
+	var was models.MatchEvent
+	if old != nil && old.TeamCode == e.TeamCode {
+		was = *old
+	}
+	refs := []struct {
+		field string
+		id    *string
+		name  *string
+		was   string // id in old
+	}{
+		{"player", &e.PlayerID, &e.Player, was.PlayerID},
+		{"playerOut", &e.PlayerOutID, &e.PlayerOut, was.PlayerOutID},
+		{"playerIn", &e.PlayerInID, &e.PlayerIn, was.PlayerInID},
+		{"assist", &e.AssistID, &e.Assist, was.AssistID},
+	}
+
+	var hasSquad *bool
+	for _, ref := range refs {
+		if *ref.id == "" {
+			if *ref.name == "" || allowText {
+				continue
+			}
+			if hasSquad == nil {
+				ok, err := players.HasSquad(ctx, e.TeamCode)
+				if err != nil {
+					return "", err
+				}
+				hasSquad = &ok
+			}
+			if *hasSquad {
+				return ref.field + "Id required: " + e.TeamCode + " has a registered squad", nil
+			}
+			continue
+		}
+
+		p, found, err := players.Find(ctx, *ref.id)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return ref.field + "Id " + *ref.id + " not found", nil
+		}
+		if p.TeamCode != e.TeamCode && *ref.id != ref.was {
+			return ref.field + "Id " + *ref.id + " is not registered to " + e.TeamCode, nil
+		}
+		*ref.name = p.Name
+	}
+	return "", nil
+}
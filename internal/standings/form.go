@@ -0,0 +1,51 @@
+package standings
+
+import "final-by-me/internal/models"
+
+// formLength is how many results the form guide shows.
+const formLength = 5
+
+// played is one counted result of a team, with its own goals first.
+type played struct {
+	form   models.FormResult
+	gf, ga int
+}
+
+// form returns the last formLength results and the current streaks of a
+// team's chronological results.
+func form(results []played) ([]models.FormResult, models.Streaks) {
+	var st models.Streaks
+	unbeaten, winless, scoring, clean := true, true, true, true
+	for i := len(results) - 1; i >= 0; i-- {
+		p := results[i]
+		unbeaten = unbeaten && p.gf >= p.ga
+		winless = winless && p.gf <= p.ga
+		scoring = scoring && p.gf > 0
+		clean = clean && p.ga == 0
+		if unbeaten {
+			st.Unbeaten++
+		}
+		if winless {
+			st.Winless++
+		}
+		if scoring {
+			st.Scoring++
+		}
+		if clean {
+			st.CleanSheet++
+		}
+		if !unbeaten && !winless && !scoring && !clean {
+			break
+		}
+	}
+
+	last := results
+	if len(last) > formLength {
+		last = last[len(last)-formLength:]
+	}
+	out := make([]models.FormResult, len(last))
+	for i, p := range last {
+		out[i] = p.form
+	}
+	return out, st
+}
@@ -0,0 +1,89 @@
+package standings
+
+import (
+	"sort"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+// Matchdays returns the matchday of every line by matchKey: the stored
+// Matchday when set, otherwise the round after the latest matchday either
+// team has played, stored or inferred. Lines only hold matches with a result,
+// so stored matchdays keep postponed rounds from shifting the rest.
+func Matchdays(lines []models.ResultLine) map[string]int {
+	sorted := append([]models.ResultLine{}, lines...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].DateTime.Equal(sorted[j].DateTime) {
+			return sorted[i].DateTime.Before(sorted[j].DateTime)
+		}
+		return sorted[i].MatchKey < sorted[j].MatchKey
+	})
+
+	out := make(map[string]int, len(sorted))
+	latest := map[string]int{}
+	for _, l := range sorted {
+		md := l.Matchday
+		if md == 0 {
+			md = max(latest[l.HomeCode], latest[l.AwayCode]) + 1
+		}
+		latest[l.HomeCode] = max(latest[l.HomeCode], md)
+		latest[l.AwayCode] = max(latest[l.AwayCode], md)
+		out[l.MatchKey] = md
+	}
+	return out
+}
+
+// Until keeps the lines whose result was final at asOf (zero: no limit) and
+// whose matchday is at most matchday (0: no limit).
+func Until(lines []models.ResultLine, asOf time.Time, matchday int) []models.ResultLine {
+	var days map[string]int
+	if matchday > 0 {
+		days = Matchdays(lines)
+	}
+	var out []models.ResultLine
+	for _, l := range lines {
+		if !asOf.IsZero() && l.ResultAt.After(asOf) {
+			continue
+		}
+		if matchday > 0 && days[l.MatchKey] > matchday {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// Position is a team's place in the table after one matchday.
+type Position struct {
+	Matchday int `json:"matchday"`
+	Position int `json:"position"`
+	Points   int `json:"points"`
+	Played   int `json:"played"`
+}
+
+// History returns the position of team after every matchday of lines.
+func History(team string, teams []models.Team, lines []models.ResultLine, rules models.LeagueRules) []Position {
+	days := Matchdays(lines)
+	last := 0
+	for _, d := range days {
+		last = max(last, d)
+	}
+
+	out := make([]Position, 0, last)
+	for d := 1; d <= last; d++ {
+		var upTo []models.ResultLine
+		for _, l := range lines {
+			if days[l.MatchKey] <= d {
+				upTo = append(upTo, l)
+			}
+		}
+		for i, r := range BuildLines(teams, upTo, rules, models.ViewOverall) {
+			if r.TeamCode == team {
+				out = append(out, Position{Matchday: d, Position: i + 1, Points: r.Pts, Played: r.P})
+				break
+			}
+		}
+	}
+	return out
+}
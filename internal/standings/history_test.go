@@ -0,0 +1,48 @@
+package standings
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"final-by-me/internal/models"
+)
+
+func TestMatchdays(t *testing.T) {
+	day := func(d, matchday int, key, home, away string) models.ResultLine {
+		return models.ResultLine{
+			MatchKey: key, Matchday: matchday, HomeCode: home, AwayCode: away,
+			DateTime: time.Date(2030, 8, d, 15, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		lines []models.ResultLine
+		want  map[string]int
+	}{
+		{
+			name:  "inferred from rounds played",
+			lines: []models.ResultLine{day(1, 0, "m1", "AAA", "BBB"), day(1, 0, "m2", "CCC", "DDD"), day(8, 0, "m3", "AAA", "CCC")},
+			want:  map[string]int{"m1": 1, "m2": 1, "m3": 2},
+		},
+		{
+			// round 2 of AAA was postponed: its stored round 3 wins
+			name:  "stored matchday",
+			lines: []models.ResultLine{day(1, 1, "m1", "AAA", "BBB"), day(15, 3, "m3", "AAA", "CCC")},
+			want:  map[string]int{"m1": 1, "m3": 3},
+		},
+		{
+			name:  "inferred after a stored matchday",
+			lines: []models.ResultLine{day(1, 1, "m1", "AAA", "BBB"), day(15, 3, "m3", "AAA", "CCC"), day(22, 0, "m4", "AAA", "DDD")},
+			want:  map[string]int{"m1": 1, "m3": 3, "m4": 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matchdays(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Matchdays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
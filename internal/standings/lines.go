@@ -0,0 +1,56 @@
+package standings
+
+import "final-by-me/internal/models"
+
+// Lines returns the result lines of the matches that have a result.
+func Lines(matches []models.Match) []models.ResultLine {
+	out := make([]models.ResultLine, 0, len(matches))
+	for _, m := range matches {
+		if m.Status.HasResult() {
+			out = append(out, LineOf(m))
+		}
+	}
+	return out
+}
+
+// LineOf reduces m to what the table needs, whatever its status. The Mongo
+// repository computes the same in MatchRepo.ResultLines.
+func LineOf(m models.Match) models.ResultLine {
+	hg, ag := m.Result()
+	l := models.ResultLine{
+		MatchKey:  m.MatchKey,
+		Season:    m.Season,
+		DateTime:  m.DateTime,
+		ResultAt:  m.ResultAt(),
+		Matchday:  m.Matchday,
+		HomeCode:  m.HomeCode,
+		AwayCode:  m.AwayCode,
+		HomeGoals: hg,
+		AwayGoals: ag,
+	}
+	if m.Level() && m.Shootout != nil && m.Shootout.Completed {
+		l.ShootoutWinner = m.Shootout.Winner
+	}
+	for _, e := range m.Events {
+		if e.Type != models.EventCard {
+			continue
+		}
+		switch e.TeamCode {
+		case m.HomeCode:
+			l.HomeFairPlay += cardPoints(e.CardColor)
+		case m.AwayCode:
+			l.AwayFairPlay += cardPoints(e.CardColor)
+		}
+	}
+	return l
+}
+
+func cardPoints(color string) int {
+	switch color {
+	case "yellow":
+		return models.FairPlayYellow
+	case "red":
+		return models.FairPlayRed
+	}
+	return 0
+}
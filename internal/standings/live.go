@@ -0,0 +1,39 @@
+package standings
+
+import "final-by-me/internal/models"
+
+// AsItStands returns result lines for live matches as if they ended now
+// with their current score.
+func AsItStands(live []models.Match) []models.ResultLine {
+	out := make([]models.ResultLine, 0, len(live))
+	for _, m := range live {
+		out = append(out, LineOf(m))
+	}
+	return out
+}
+
+// Provisional returns the provisional table with every row marked against
+// the table without the live matches: change of position and points, and
+// whether the team is playing one of them.
+func Provisional(before, provisional []models.TableRow, live []models.Match) []models.TableRow {
+	pos := make(map[string]int, len(before))
+	pts := make(map[string]int, len(before))
+	for i, r := range before {
+		pos[r.TeamCode] = i + 1
+		pts[r.TeamCode] = r.Pts
+	}
+	playing := map[string]bool{}
+	for _, m := range live {
+		playing[m.HomeCode] = true
+		playing[m.AwayCode] = true
+	}
+
+	out := make([]models.TableRow, len(provisional))
+	for i, r := range provisional {
+		r.Playing = playing[r.TeamCode]
+		r.PositionChange = pos[r.TeamCode] - (i + 1)
+		r.PointsChange = r.Pts - pts[r.TeamCode]
+		out[i] = r
+	}
+	return out
+}
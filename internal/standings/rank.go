@@ -0,0 +1,106 @@
+package standings
+
+import (
+	"sort"
+
+	"final-by-me/internal/models"
+)
+
+// Rank orders rows by points, then splits every group of teams level on
+// points with the tie-breakers of rules in order. Each tie-breaker only
+// reorders teams still level after the previous ones, and head-to-head
+// criteria are computed over the matches between exactly those teams, scored
+// with the league's points. Teams level on everything are ordered by name so
+// the table is stable.
+func Rank(rows []*models.TableRow, lines []models.ResultLine, rules models.LeagueRules) {
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].TeamName < rows[j].TeamName })
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Pts > rows[j].Pts })
+
+	for _, g := range groups(rows, func(r *models.TableRow) int { return r.Pts }) {
+		breakTie(g, lines, rules, rules.TieBreakers)
+	}
+}
+
+func breakTie(group []*models.TableRow, lines []models.ResultLine, rules models.LeagueRules, tieBreakers []models.TieBreaker) {
+	if len(group) < 2 || len(tieBreakers) == 0 {
+		return
+	}
+
+	key := criterion(tieBreakers[0], group, lines, rules)
+	sort.SliceStable(group, func(i, j int) bool { return key(group[i]) > key(group[j]) })
+
+	for _, g := range groups(group, key) {
+		breakTie(g, lines, rules, tieBreakers[1:])
+	}
+}
+
+// criterion returns the sort key for t among group; higher ranks first.
+func criterion(t models.TieBreaker, group []*models.TableRow, lines []models.ResultLine, rules models.LeagueRules) func(*models.TableRow) int {
+	switch t {
+	case models.TieGoalDifference:
+		return func(r *models.TableRow) int { return r.GD }
+	case models.TieGoalsFor:
+		return func(r *models.TableRow) int { return r.GF }
+	case models.TieAwayGoals:
+		return func(r *models.TableRow) int { return r.AwayGF }
+	case models.TieWins:
+		return func(r *models.TableRow) int { return r.W }
+	case models.TieFairPlay:
+		return func(r *models.TableRow) int { return -r.FairPlay }
+	case models.TieHeadToHeadPoints:
+		h2h := headToHead(group, lines, rules)
+		return func(r *models.TableRow) int { return h2h[r.TeamCode].pts }
+	case models.TieHeadToHeadGD:
+		h2h := headToHead(group, lines, rules)
+		return func(r *models.TableRow) int { return h2h[r.TeamCode].gd }
+	}
+	return func(*models.TableRow) int { return 0 }
+}
+
+type miniRow struct{ pts, gd int }
+
+// headToHead is the mini-league of the matches played between the teams of
+// group, with the points of rules.
+func headToHead(group []*models.TableRow, lines []models.ResultLine, rules models.LeagueRules) map[string]miniRow {
+	in := make(map[string]bool, len(group))
+	for _, r := range group {
+		in[r.TeamCode] = true
+	}
+	out := make(map[string]miniRow, len(group))
+	for _, m := range lines {
+		if !in[m.HomeCode] || !in[m.AwayCode] {
+			continue
+		}
+		hg, ag := m.HomeGoals, m.AwayGoals
+		h, a := out[m.HomeCode], out[m.AwayCode]
+		h.gd += hg - ag
+		a.gd += ag - hg
+		switch {
+		case hg > ag:
+			h.pts += rules.PointsWin
+			a.pts += rules.PointsLoss
+		case hg < ag:
+			h.pts += rules.PointsLoss
+			a.pts += rules.PointsWin
+		default:
+			h.pts += rules.PointsDraw
+			a.pts += rules.PointsDraw
+		}
+		out[m.HomeCode], out[m.AwayCode] = h, a
+	}
+	return out
+}
+
+// groups splits sorted rows into runs with the same key.
+func groups(rows []*models.TableRow, key func(*models.TableRow) int) [][]*models.TableRow {
+	var out [][]*models.TableRow
+	for i := 0; i < len(rows); {
+		j := i + 1
+		for j < len(rows) && key(rows[j]) == key(rows[i]) {
+			j++
+		}
+		out = append(out, rows[i:j])
+		i = j
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package standings
+
+import (
+	"reflect"
+	"testing"
+
+	"final-by-me/internal/models"
+)
+
+func row(code string, pts, gd, gf int) *models.TableRow {
+	return &models.TableRow{TeamCode: code, TeamName: code, Pts: pts, GD: gd, GF: gf}
+}
+
+func line(home, away string, hg, ag int) models.ResultLine {
+	return models.ResultLine{HomeCode: home, AwayCode: away, HomeGoals: hg, AwayGoals: ag}
+}
+
+func TestRank(t *testing.T) {
+	std := models.LeagueRules{PointsWin: 3, PointsDraw: 1}
+	withTies := func(r models.LeagueRules, tb ...models.TieBreaker) models.LeagueRules {
+		r.TieBreakers = tb
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		rows  []*models.TableRow
+		lines []models.ResultLine
+		rules models.LeagueRules
+		want  []string
+	}{
+		{
+			name:  "points",
+			rows:  []*models.TableRow{row("AAA", 10, 9, 20), row("BBB", 7, 0, 5), row("CCC", 12, -3, 8)},
+			rules: withTies(std, models.TieGoalDifference),
+			want:  []string{"CCC", "AAA", "BBB"},
+		},
+		{
+			name:  "goal difference",
+			rows:  []*models.TableRow{row("AAA", 10, 2, 20), row("BBB", 10, 5, 8), row("CCC", 7, 9, 30)},
+			rules: withTies(std, models.TieGoalDifference, models.TieGoalsFor),
+			want:  []string{"BBB", "AAA", "CCC"},
+		},
+		{
+			name:  "goals scored",
+			rows:  []*models.TableRow{row("AAA", 10, 3, 8), row("BBB", 10, 3, 11), row("CCC", 10, 1, 15)},
+			rules: withTies(std, models.TieGoalDifference, models.TieGoalsFor),
+			want:  []string{"BBB", "AAA", "CCC"},
+		},
+		{
+			// h2h points: AAA 4, BBB 3, CCC 1; overall GD says the opposite
+			name: "head-to-head points in a 3-way tie",
+			rows: []*models.TableRow{row("AAA", 10, 1, 9), row("BBB", 10, 3, 9), row("CCC", 10, 5, 9)},
+			lines: []models.ResultLine{
+				line("AAA", "BBB", 2, 0),
+				line("AAA", "CCC", 1, 1),
+				line("BBB", "CCC", 1, 0),
+				line("AAA", "DDD", 0, 4), // DDD is not level: ignored
+			},
+			rules: withTies(std, models.TieHeadToHeadPoints, models.TieGoalDifference),
+			want:  []string{"AAA", "BBB", "CCC"},
+		},
+		{
+			// a cycle: level on h2h points (3 each), split by h2h GD
+			name: "head-to-head goal difference in a 3-way tie",
+			rows: []*models.TableRow{row("AAA", 10, 0, 9), row("BBB", 10, 0, 9), row("CCC", 10, 0, 9)},
+			lines: []models.ResultLine{
+				line("AAA", "BBB", 1, 0),
+				line("BBB", "CCC", 3, 0),
+				line("CCC", "AAA", 1, 0),
+			},
+			rules: withTies(std, models.TieHeadToHeadPoints, models.TieHeadToHeadGD),
+			want:  []string{"BBB", "AAA", "CCC"},
+		},
+		{
+			// with 2/1/0 AAA (W, L) and BBB (D, D) are level in the
+			// mini-league, which 3/1/0 would have split
+			name: "head-to-head with the league's points",
+			rows: []*models.TableRow{row("AAA", 8, 1, 9), row("BBB", 8, 5, 9), row("CCC", 8, 0, 9)},
+			lines: []models.ResultLine{
+				line("AAA", "BBB", 1, 0),
+				line("CCC", "AAA", 1, 0),
+				line("BBB", "CCC", 0, 0),
+				line("CCC", "BBB", 1, 1),
+			},
+			rules: withTies(models.LeagueRules{PointsWin: 2, PointsDraw: 1}, models.TieHeadToHeadPoints, models.TieGoalDifference),
+			want:  []string{"CCC", "BBB", "AAA"},
+		},
+		{
+			name: "away goals",
+			rows: []*models.TableRow{
+				{TeamCode: "AAA", TeamName: "AAA", Pts: 10, GD: 2, GF: 9, AwayGF: 2},
+				{TeamCode: "BBB", TeamName: "BBB", Pts: 10, GD: 2, GF: 9, AwayGF: 4},
+				{TeamCode: "CCC", TeamName: "CCC", Pts: 10, GD: 2, GF: 9, AwayGF: 5},
+			},
+			rules: withTies(std, models.TieGoalDifference, models.TieGoalsFor, models.TieAwayGoals),
+			want:  []string{"CCC", "BBB", "AAA"},
+		},
+		{
+			name: "wins",
+			rows: []*models.TableRow{
+				{TeamCode: "AAA", TeamName: "AAA", Pts: 10, GD: 2, GF: 9, W: 2},
+				{TeamCode: "BBB", TeamName: "BBB", Pts: 10, GD: 2, GF: 9, W: 3},
+				{TeamCode: "CCC", TeamName: "CCC", Pts: 10, GD: 2, GF: 9, W: 1},
+			},
+			rules: withTies(std, models.TieGoalDifference, models.TieWins),
+			want:  []string{"BBB", "AAA", "CCC"},
+		},
+		{
+			// fewer card points ranks higher
+			name: "fair play",
+			rows: []*models.TableRow{
+				{TeamCode: "AAA", TeamName: "AAA", Pts: 10, GD: 2, GF: 9, FairPlay: 9},
+				{TeamCode: "BBB", TeamName: "BBB", Pts: 10, GD: 2, GF: 9, FairPlay: 3},
+				{TeamCode: "CCC", TeamName: "CCC", Pts: 10, GD: 2, GF: 9, FairPlay: 5},
+			},
+			rules: withTies(std, models.TieGoalDifference, models.TieFairPlay),
+			want:  []string{"BBB", "CCC", "AAA"},
+		},
+		{
+			name:  "level on everything falls back to name",
+			rows:  []*models.TableRow{row("CCC", 10, 2, 5), row("AAA", 10, 2, 5), row("BBB", 10, 2, 5)},
+			rules: withTies(std, models.TieGoalDifference, models.TieGoalsFor),
+			want:  []string{"AAA", "BBB", "CCC"},
+		},
+		{
+			name:  "no tie-breakers falls back to name",
+			rows:  []*models.TableRow{row("CCC", 10, 9, 5), row("BBB", 12, 0, 0), row("AAA", 10, 1, 5)},
+			rules: std,
+			want:  []string{"BBB", "AAA", "CCC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Rank(tt.rows, tt.lines, tt.rules)
+			got := make([]string, len(tt.rows))
+			for i, r := range tt.rows {
+				got[i] = r.TeamCode
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package standings builds league tables from match results.
+package standings
+
+import (
+	"fmt"
+	"sort"
+
+	"final-by-me/internal/models"
+)
+
+// Build returns the overall table for teams from the given matches under rules.
+func Build(teams []models.Team, matches []models.Match, rules models.LeagueRules) []models.TableRow {
+	return BuildLines(teams, Lines(matches), rules, models.ViewOverall)
+}
+
+// BuildLines returns the table for teams from result lines under rules. Only
+// lines where both sides are in teams count; awarded matches already carry
+// the league's score. view home/away counts only the teams' home/away
+// matches, including for form and streaks.
+func BuildLines(teams []models.Team, lines []models.ResultLine, rules models.LeagueRules, view string) []models.TableRow {
+	rows := make(map[string]*models.TableRow, len(teams))
+	for _, t := range teams {
+		rows[t.Code] = &models.TableRow{TeamCode: t.Code, TeamName: t.Name, League: t.League}
+	}
+
+	// chronological, so form and streaks read in order
+	sorted := append([]models.ResultLine{}, lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DateTime.Before(sorted[j].DateTime) })
+
+	results := map[string][]played{}
+	var counted []models.ResultLine
+	for _, l := range sorted {
+		home := rows[l.HomeCode]
+		away := rows[l.AwayCode]
+		if home == nil || away == nil {
+			continue
+		}
+		counted = append(counted, l)
+
+		hg, ag := l.HomeGoals, l.AwayGoals
+		if view != models.ViewAway {
+			addResult(home, hg, ag, rules)
+			home.FairPlay += l.HomeFairPlay
+			results[l.HomeCode] = append(results[l.HomeCode], played{formResult(l, true), hg, ag})
+		}
+		if view != models.ViewHome {
+			addResult(away, ag, hg, rules)
+			away.AwayGF += ag
+			away.FairPlay += l.AwayFairPlay
+			results[l.AwayCode] = append(results[l.AwayCode], played{formResult(l, false), ag, hg})
+		}
+
+		if w := rows[l.ShootoutWinner]; w != nil && counts(view, l, w.TeamCode) {
+			w.Bonus += shootoutBonus(rules)
+		}
+	}
+
+	out := make([]*models.TableRow, 0, len(rows))
+	for code, r := range rows {
+		r.GD = r.GF - r.GA
+		r.Pts += r.Bonus
+		r.Form, r.Streaks = form(results[code])
+		out = append(out, r)
+	}
+
+	Rank(out, counted, rules)
+
+	table := make([]models.TableRow, len(out))
+	for i, r := range out {
+		table[i] = *r
+	}
+	return table
+}
+
+// counts reports whether team's side of l is part of view.
+func counts(view string, l models.ResultLine, team string) bool {
+	switch view {
+	case models.ViewHome:
+		return team == l.HomeCode
+	case models.ViewAway:
+		return team == l.AwayCode
+	}
+	return true
+}
+
+func formResult(l models.ResultLine, home bool) models.FormResult {
+	gf, ga, opp := l.HomeGoals, l.AwayGoals, l.AwayCode
+	if !home {
+		gf, ga, opp = l.AwayGoals, l.HomeGoals, l.HomeCode
+	}
+	f := models.FormResult{MatchKey: l.MatchKey, Opponent: opp, Home: home, Score: fmt.Sprintf("%d-%d", gf, ga)}
+	switch {
+	case gf > ga:
+		f.Result = "W"
+	case gf < ga:
+		f.Result = "L"
+	default:
+		f.Result = "D"
+	}
+	return f
+}
+
+// addResult books one match for row r that scored gf and conceded ga.
+func addResult(r *models.TableRow, gf, ga int, rules models.LeagueRules) {
+	r.P++
+	r.GF += gf
+	r.GA += ga
+	switch {
+	case gf > ga:
+		r.W++
+		r.Pts += rules.PointsWin
+	case gf < ga:
+		r.L++
+		r.Pts += rules.PointsLoss
+	default:
+		r.D++
+		r.Pts += rules.PointsDraw
+	}
+	r.Bonus += bonus(rules, gf, ga)
+}
+
+// bonus sums the bonus points earned by a side that scored gf and conceded ga.
+func bonus(rules models.LeagueRules, gf, ga int) int {
+	pts := 0
+	for _, b := range rules.Bonuses {
+		switch b.Kind {
+		case models.BonusGoalsScored:
+			if gf >= b.Threshold {
+				pts += b.Points
+			}
+		case models.BonusCleanSheet:
+			if ga == 0 {
+				pts += b.Points
+			}
+		case models.BonusNarrowLoss:
+			if gf < ga && ga-gf <= b.Threshold {
+				pts += b.Points
+			}
+		}
+	}
+	return pts
+}
+
+func shootoutBonus(rules models.LeagueRules) int {
+	pts := 0
+	for _, b := range rules.Bonuses {
+		if b.Kind == models.BonusShootoutWin {
+			pts += b.Points
+		}
+	}
+	return pts
+}
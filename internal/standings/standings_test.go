@@ -0,0 +1,79 @@
+package standings
+
+import (
+	"testing"
+
+	"final-by-me/internal/models"
+)
+
+func TestBonuses(t *testing.T) {
+	teams := []models.Team{{Code: "AAA", Name: "AAA"}, {Code: "BBB", Name: "BBB"}}
+	result := func(hg, ag int, shootout string) []models.ResultLine {
+		return []models.ResultLine{{MatchKey: "m1", HomeCode: "AAA", AwayCode: "BBB", HomeGoals: hg, AwayGoals: ag, ShootoutWinner: shootout}}
+	}
+
+	tests := []struct {
+		name     string
+		bonus    models.Bonus
+		lines    []models.ResultLine
+		bonusAAA int
+		bonusBBB int
+		ptsAAA   int
+	}{
+		{
+			name:     "goals scored",
+			bonus:    models.Bonus{Kind: models.BonusGoalsScored, Threshold: 4, Points: 1},
+			lines:    result(4, 3, ""),
+			bonusAAA: 1,
+			ptsAAA:   4,
+		},
+		{
+			name:   "goals scored below the threshold",
+			bonus:  models.Bonus{Kind: models.BonusGoalsScored, Threshold: 4, Points: 1},
+			lines:  result(3, 0, ""),
+			ptsAAA: 3,
+		},
+		{
+			name:     "clean sheet",
+			bonus:    models.Bonus{Kind: models.BonusCleanSheet, Points: 2},
+			lines:    result(1, 0, ""),
+			bonusAAA: 2,
+			ptsAAA:   5,
+		},
+		{
+			name:     "narrow loss",
+			bonus:    models.Bonus{Kind: models.BonusNarrowLoss, Threshold: 1, Points: 1},
+			lines:    result(2, 1, ""),
+			bonusBBB: 1,
+			ptsAAA:   3,
+		},
+		{
+			name:   "heavier loss",
+			bonus:  models.Bonus{Kind: models.BonusNarrowLoss, Threshold: 1, Points: 1},
+			lines:  result(3, 1, ""),
+			ptsAAA: 3,
+		},
+		{
+			name:     "shootout win",
+			bonus:    models.Bonus{Kind: models.BonusShootoutWin, Points: 1},
+			lines:    result(1, 1, "BBB"),
+			bonusBBB: 1,
+			ptsAAA:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := models.LeagueRules{PointsWin: 3, PointsDraw: 1, Bonuses: []models.Bonus{tt.bonus}}
+			got := map[string]models.TableRow{}
+			for _, r := range BuildLines(teams, tt.lines, rules, models.ViewOverall) {
+				got[r.TeamCode] = r
+			}
+			if got["AAA"].Bonus != tt.bonusAAA || got["BBB"].Bonus != tt.bonusBBB {
+				t.Fatalf("bonus AAA %d, BBB %d, want %d, %d", got["AAA"].Bonus, got["BBB"].Bonus, tt.bonusAAA, tt.bonusBBB)
+			}
+			if got["AAA"].Pts != tt.ptsAAA {
+				t.Fatalf("AAA pts = %d, want %d", got["AAA"].Pts, tt.ptsAAA)
+			}
+		})
+	}
+}
@@ -11,11 +11,11 @@ import (
 
 type EventWorker struct {
 	ch   <-chan models.EventLog
-	repo *repository.EventRepo
+	repo repository.EventStore
 	stop context.CancelFunc
 }
 
-func StartEventWorker(repo *repository.EventRepo, buffer int) (chan<- models.EventLog, func()) {
+func StartEventWorker(repo repository.EventStore, buffer int) (chan<- models.EventLog, func()) {
 	ch := make(chan models.EventLog, buffer)
 
 	ctx, cancel := context.WithCancel(context.Background())
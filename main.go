@@ -7,23 +7,16 @@ import (
 	"os"
 	"time"
 
-	"final-by-me/internal/db"
 	"final-by-me/internal/handlers"
+	"final-by-me/internal/live"
 	"final-by-me/internal/middleware"
-	"final-by-me/internal/repository"
 	"final-by-me/internal/seed"
 	"final-by-me/internal/worker"
 )
 
 func main() {
-	mongoURI := os.Getenv("MONGO_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017"
-	}
-
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "EPL-Connect"
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
 	}
 
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
@@ -36,18 +29,18 @@ func main() {
 		port = "8081"
 	}
 
-	// DB connect
-	client, err := db.Connect(mongoURI)
-	if err != nil {
-		log.Fatal("Mongo connect error:", err)
-	}
-	defer func() { _ = client.Disconnect(context.Background()) }()
-
-	database := client.Database(dbName)
+	// DB connect (STORAGE=mongo|memory)
+	st, closeStores := openStores()
+	defer closeStores()
 
-	teamRepo := repository.NewTeamRepo(database)
-	matchRepo := repository.NewMatchRepo(database)
-	eventRepo := repository.NewEventRepo(database)
+	teamRepo := st.teams
+	matchRepo := st.matches
+	seasonRepo := st.seasons
+	rulesRepo := st.rules
+	standingsRepo := st.standings
+	eventRepo := st.events
+	userRepo := st.users
+	playerRepo := st.players
 
 	// background worker
 	eventCh, stopWorker := worker.StartEventWorker(eventRepo, 100)
@@ -63,19 +56,49 @@ func main() {
 	if err := matchRepo.EnsureIndexes(ctx); err != nil {
 		log.Fatal("match index error:", err)
 	}
+	if err := seasonRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("season index error:", err)
+	}
+	if err := standingsRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("standings index error:", err)
+	}
+	if err := userRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("user index error:", err)
+	}
+	if err := playerRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("player index error:", err)
+	}
 
 	// If you use leagues, seed with TeamsAll()
 	// If you still seed EPL only, change it back.
 	if err := teamRepo.SeedIfEmpty(ctx, seed.TeamsAll()); err != nil {
 		log.Fatal("seed teams error:", err)
 	}
+	if err := rulesRepo.SeedIfEmpty(ctx, seed.Rules()); err != nil {
+		log.Fatal("seed rules error:", err)
+	}
+
+	// standings projection (GET /table reads it; results update it)
+	projector := st.projector()
+	if err := projector.BuildIfEmpty(ctx); err != nil {
+		log.Fatal("standings build error:", err)
+	}
+
+	// live updates (SSE + WebSocket scoreboard)
+	hub := live.NewHub(200)
 
 	// Handlers
-	authH := handlers.NewAuthHandler(database, jwtSecret, teamRepo)
-	teamH := handlers.NewTeamHandler(teamRepo)
-	matchH := handlers.NewMatchMongoHandler(matchRepo, teamRepo, eventCh)
-	tableH := handlers.NewTableHandler(teamRepo, matchRepo)
-	statsH := handlers.NewStatsHandler(matchRepo)
+	authH := handlers.NewAuthHandler(userRepo, jwtSecret, teamRepo)
+	teamH := handlers.NewTeamHandler(teamRepo, rulesRepo, projector)
+	matchH := handlers.NewMatchMongoHandler(matchRepo, teamRepo, seasonRepo, playerRepo, rulesRepo, projector, eventCh, hub)
+	seasonH := handlers.NewSeasonHandler(seasonRepo, teamRepo, matchRepo, rulesRepo, projector, eventCh)
+	tableH := handlers.NewTableHandler(teamRepo, matchRepo, seasonRepo, rulesRepo, projector, hub)
+	statsH := handlers.NewStatsHandler(matchRepo, teamRepo, seasonRepo)
+	playerH := handlers.NewPlayerHandler(playerRepo, teamRepo, eventCh)
+	disciplineH := handlers.NewDisciplineHandler(matchRepo, teamRepo, seasonRepo, rulesRepo)
+	calendarH := handlers.NewCalendarHandler(matchRepo, teamRepo, seasonRepo)
+	adminH := handlers.NewAdminHandler(matchRepo, eventRepo, projector)
+	boardH := handlers.NewScoreboardHandler(matchRepo, teamRepo, hub)
 
 	// Router
 	mux := http.NewServeMux()
@@ -90,11 +113,26 @@ func main() {
 	mux.HandleFunc("POST /auth/login", authH.Login)
 
 	mux.HandleFunc("GET /leagues", teamH.ListLeagues)
+	mux.HandleFunc("GET /leagues/{league}/rules", teamH.GetRules)
 	mux.HandleFunc("GET /teams", teamH.ListTeams)
+	mux.HandleFunc("GET /teams/{code}/positions", tableH.PositionHistory)
+	mux.HandleFunc("GET /teams/{code}/players", playerH.ListSquad)
+	mux.HandleFunc("GET /players/{id}", playerH.GetPlayer)
 
+	mux.HandleFunc("GET /seasons", seasonH.ListSeasons)
+	mux.HandleFunc("GET /seasons/{id}", seasonH.GetSeason)
 	mux.HandleFunc("GET /matches", matchH.ListMatches)
+	mux.HandleFunc("GET /matches/{key}", matchH.GetMatch)
+	mux.HandleFunc("GET /matches/{key}/stream", matchH.StreamMatch)
+	mux.HandleFunc("GET /ws/scoreboard", boardH.Connect)
 	mux.HandleFunc("GET /table", tableH.GetTable)
+	mux.HandleFunc("GET /table/stream", tableH.StreamTable)
 	mux.HandleFunc("GET /stats", statsH.GetStats)
+	mux.HandleFunc("GET /stats/scorers", statsH.TopScorers)
+	mux.HandleFunc("GET /stats/assists", statsH.TopAssists)
+	mux.HandleFunc("GET /discipline", disciplineH.GetDiscipline)
+	mux.HandleFunc("GET /calendar/teams/{file}", calendarH.TeamFeed)
+	mux.HandleFunc("GET /calendar/leagues/{file}", calendarH.LeagueFeed)
 
 	// Admin-only chain
 	adminChain := func(h http.Handler) http.Handler {
@@ -106,10 +144,32 @@ func main() {
 	}
 
 	// Admin routes MUST match UI calls (NO conflicts)
+	mux.Handle("PUT /leagues/{league}/rules", adminChain(http.HandlerFunc(teamH.PutRules)))
+	mux.Handle("PATCH /teams/{code}", adminChain(http.HandlerFunc(teamH.UpdateTeam)))
+	mux.Handle("POST /leagues/{league}/fixtures", adminChain(http.HandlerFunc(matchH.GenerateFixtures)))
+	mux.Handle("POST /teams/{code}/players", adminChain(http.HandlerFunc(playerH.AddPlayer)))
+	mux.Handle("PATCH /teams/{code}/players/{id}", adminChain(http.HandlerFunc(playerH.UpdatePlayer)))
+	mux.Handle("DELETE /teams/{code}/players/{id}", adminChain(http.HandlerFunc(playerH.ReleasePlayer)))
+	mux.Handle("POST /seasons", adminChain(http.HandlerFunc(seasonH.CreateSeason)))
+	mux.Handle("POST /seasons/{id}/close", adminChain(http.HandlerFunc(seasonH.CloseSeason)))
 	mux.Handle("POST /matches", adminChain(http.HandlerFunc(matchH.CreateMatch)))
+	mux.Handle("POST /matches/import", adminChain(http.HandlerFunc(matchH.ImportMatches)))
 	mux.Handle("PATCH /matches/{key}/events", adminChain(http.HandlerFunc(matchH.AddEvent)))
+	mux.Handle("PUT /matches/{key}/events/{id}", adminChain(http.HandlerFunc(matchH.UpdateEvent)))
+	mux.Handle("DELETE /matches/{key}/events/{id}", adminChain(http.HandlerFunc(matchH.DeleteEvent)))
+	mux.Handle("PATCH /matches/{key}/period", adminChain(http.HandlerFunc(matchH.SetPeriod)))
+	mux.Handle("POST /matches/{key}/shootout/kicks", adminChain(http.HandlerFunc(matchH.AddShootoutKick)))
 	mux.Handle("PATCH /matches/{key}/status", adminChain(http.HandlerFunc(matchH.SetStatus)))
 	mux.Handle("POST /matches/{key}/finalize", adminChain(http.HandlerFunc(matchH.Finalize)))
+	mux.Handle("PATCH /matches/{key}", adminChain(http.HandlerFunc(matchH.UpdateMatch)))
+	mux.Handle("POST /matches/{key}/postpone", adminChain(http.HandlerFunc(matchH.Postpone)))
+	mux.Handle("POST /matches/{key}/reschedule", adminChain(http.HandlerFunc(matchH.Reschedule)))
+	mux.Handle("POST /matches/{key}/resume", adminChain(http.HandlerFunc(matchH.Resume)))
+	mux.Handle("POST /matches/{key}/replay", adminChain(http.HandlerFunc(matchH.Replay)))
+	mux.Handle("POST /matches/{key}/award", adminChain(http.HandlerFunc(matchH.AwardMatch)))
+	mux.Handle("POST /admin/scores/reconcile", adminChain(http.HandlerFunc(adminH.ReconcileScores)))
+	mux.Handle("POST /admin/standings/rebuild", adminChain(http.HandlerFunc(adminH.RebuildStandings)))
+	mux.Handle("POST /admin/standings/check", adminChain(http.HandlerFunc(adminH.CheckStandings)))
 
 	addr := ":" + port
 	log.Println("Listening on", addr)
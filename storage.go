@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"final-by-me/internal/db"
+	"final-by-me/internal/projection"
+	"final-by-me/internal/repository"
+	"final-by-me/internal/repository/memory"
+)
+
+// stores groups every storage backend the server uses.
+type stores struct {
+	teams     repository.TeamStore
+	matches   repository.MatchStore
+	seasons   repository.SeasonStore
+	players   repository.PlayerStore
+	rules     repository.RulesStore
+	standings repository.StandingsStore
+	events    repository.EventStore
+	users     repository.UserStore
+}
+
+// projector builds the standings projection over st.
+func (st stores) projector() *projection.Projector {
+	return projection.New(st.standings, st.matches, st.teams, st.seasons, st.rules)
+}
+
+// openStores picks the backend from STORAGE (mongo | memory, default mongo).
+// The returned func releases the backend.
+func openStores() (stores, func()) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE"))) {
+	case "memory":
+		log.Println("storage: in-memory (data is lost on restart)")
+		return stores{
+			teams:     memory.NewTeamRepo(),
+			matches:   memory.NewMatchRepo(),
+			seasons:   memory.NewSeasonRepo(),
+			players:   memory.NewPlayerRepo(),
+			rules:     memory.NewRulesRepo(),
+			standings: memory.NewStandingsRepo(),
+			events:    memory.NewEventRepo(),
+			users:     memory.NewUserRepo(),
+		}, func() {}
+
+	case "", "mongo":
+		mongoURI := os.Getenv("MONGO_URI")
+		if mongoURI == "" {
+			mongoURI = "mongodb://localhost:27017"
+		}
+
+		dbName := os.Getenv("DB_NAME")
+		if dbName == "" {
+			dbName = "EPL-Connect"
+		}
+
+		client, err := db.Connect(mongoURI)
+		if err != nil {
+			log.Fatal("Mongo connect error:", err)
+		}
+		database := client.Database(dbName)
+
+		return stores{
+			teams:     repository.NewTeamRepo(database),
+			matches:   repository.NewMatchRepo(database),
+			seasons:   repository.NewSeasonRepo(database),
+			players:   repository.NewPlayerRepo(database),
+			rules:     repository.NewRulesRepo(database),
+			standings: repository.NewStandingsRepo(database),
+			events:    repository.NewEventRepo(database),
+			users:     repository.NewUserRepo(database),
+		}, func() { _ = client.Disconnect(context.Background()) }
+
+	default:
+		log.Fatal("STORAGE must be mongo|memory")
+	}
+	return stores{}, nil
+}